@@ -1,35 +1,19 @@
-package main
+package {{.Name}}
 
 import (
-	"fmt"
-	"io"
-	"log"
-	"os"
-	"strings"
+	"context"
+
+	"github.com/kentquirk/aoc2023/runner"
 )
 
-func part1(lines []string) int {
-	return 0
+func part1(ctx context.Context, lines []string) (int, error) {
+	return 0, nil
 }
 
-func part2(lines []string) int {
-	return 0
+func part2(ctx context.Context, lines []string) (int, error) {
+	return 0, nil
 }
 
-func main() {
-	args := os.Args[1:]
-	name := "sample"
-	if len(args) > 0 {
-		name = args[0]
-	}
-	f, err := os.Open(fmt.Sprintf("./data/%s.txt", name))
-	if err != nil {
-		log.Fatal(err)
-	}
-	b, err := io.ReadAll(f)
-	if err != nil {
-		log.Fatal(err)
-	}
-	lines := strings.Split(string(b), "\n")
-	fmt.Println(part1(lines))
+func init() {
+	runner.Register(runner.Day{Number: {{.Number}}, Part1: part1, Part2: part2})
 }
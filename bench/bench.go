@@ -0,0 +1,192 @@
+// Package bench times the solvers so that slow days can be compared from one
+// commit to the next. Each part is run several times against the same input
+// and the wall time and allocations are recorded, and then once more with
+// the heap watched to find its peak.
+package bench
+
+import (
+	"context"
+	"runtime"
+	"runtime/metrics"
+	"sync"
+	"time"
+
+	"github.com/kentquirk/aoc2023/runner"
+)
+
+// Result holds the measurements for one part of one day.
+type Result struct {
+	Day     int    `json:"day"`
+	Part    int    `json:"part"`
+	Dataset string `json:"dataset"`
+	Answer  int    `json:"answer"`
+	Runs    int    `json:"runs"`
+	// Min, Mean and Max are the wall time of a single run.
+	Min  time.Duration `json:"min_ns"`
+	Mean time.Duration `json:"mean_ns"`
+	Max  time.Duration `json:"max_ns"`
+	// Allocs and Bytes are the heap allocations made by a single run.
+	Allocs uint64 `json:"allocs"`
+	Bytes  uint64 `json:"bytes"`
+	// PeakHeap is the most heap in use during the extra run made to watch
+	// it, above what was in use before it started.
+	PeakHeap uint64 `json:"peak_heap_bytes"`
+}
+
+// heapSampleInterval is how often the heap is checked while a part runs.
+const heapSampleInterval = time.Millisecond
+
+const heapMetric = "/memory/classes/heap/objects:bytes"
+
+// heapInUse reads the heap in use into s, which is reused so that reading
+// it allocates nothing.
+func heapInUse(s []metrics.Sample) uint64 {
+	metrics.Read(s)
+	return s[0].Value.Uint64()
+}
+
+// watchHeap samples the heap until stop is closed and then sends the
+// highest value it saw.
+func watchHeap(stop <-chan struct{}, peak chan<- uint64) {
+	s := []metrics.Sample{{Name: heapMetric}}
+	max := heapInUse(s)
+	t := time.NewTicker(heapSampleInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			if h := heapInUse(s); h > max {
+				max = h
+			}
+		case <-stop:
+			if h := heapInUse(s); h > max {
+				max = h
+			}
+			peak <- max
+			return
+		}
+	}
+}
+
+// measureOnce runs f a single time and returns its answer, how long it took
+// and what it allocated. Nothing else runs alongside it, so that the
+// allocation counts, which are for the whole process, are f's alone.
+func measureOnce(ctx context.Context, f runner.PartFunc, lines []string) (answer int, d time.Duration, allocs, bytes uint64, err error) {
+	runtime.GC()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	start := time.Now()
+	answer, err = f(ctx, lines)
+	d = time.Since(start)
+
+	runtime.ReadMemStats(&after)
+	return answer, d, after.Mallocs - before.Mallocs, after.TotalAlloc - before.TotalAlloc, err
+}
+
+// measurePeak runs f a single time with the heap watched, and returns the
+// most it had in use above what it started with.
+func measurePeak(ctx context.Context, f runner.PartFunc, lines []string) (uint64, error) {
+	runtime.GC()
+	base := heapInUse([]metrics.Sample{{Name: heapMetric}})
+
+	stop := make(chan struct{})
+	peaks := make(chan uint64)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		watchHeap(stop, peaks)
+	}()
+
+	_, err := f(ctx, lines)
+
+	close(stop)
+	high := <-peaks
+	wg.Wait()
+
+	if high > base {
+		return high - base, err
+	}
+	return 0, err
+}
+
+// Measure runs f n times against lines, and then once more to find its peak
+// heap. It stops at the first error, or once ctx is done.
+func Measure(ctx context.Context, f runner.PartFunc, lines []string, n int) (Result, error) {
+	if n < 1 {
+		n = 1
+	}
+	r := Result{Runs: n}
+	var total time.Duration
+	for i := 0; i < n; i++ {
+		answer, d, allocs, bytes, err := measureOnce(ctx, f, lines)
+		if err != nil {
+			return r, err
+		}
+		r.Answer = answer
+		total += d
+		if i == 0 || d < r.Min {
+			r.Min = d
+		}
+		if d > r.Max {
+			r.Max = d
+		}
+		r.Allocs += allocs
+		r.Bytes += bytes
+	}
+	r.Mean = total / time.Duration(n)
+	r.Allocs /= uint64(n)
+	r.Bytes /= uint64(n)
+	peak, err := measurePeak(ctx, f, lines)
+	if err != nil {
+		return r, err
+	}
+	r.PeakHeap = peak
+	return r, nil
+}
+
+// Options controls which solvers Run measures.
+type Options struct {
+	// Dataset says where the input comes from, as for runner.Open; if
+	// empty each day's default is used.
+	Dataset string
+	// Part is 1 or 2 to measure just that part, or 0 for both.
+	Part int
+	// Runs is how many times to run each part.
+	Runs int
+	// Root is the directory holding the dayNN directories.
+	Root string
+	// Params are passed to the solvers, as for runner.Run.
+	Params runner.Params
+}
+
+// Run measures each requested part of each day, in order.
+func Run(ctx context.Context, days []runner.Day, opts Options) ([]Result, error) {
+	var results []Result
+	ctx = runner.WithParams(ctx, opts.Params)
+	for _, d := range days {
+		name, lines, err := runner.Open(opts.Root, d, opts.Dataset)
+		if err != nil {
+			return results, err
+		}
+		for p := 1; p <= 2; p++ {
+			if opts.Part != 0 && opts.Part != p {
+				continue
+			}
+			f := d.Part(p)
+			if f == nil {
+				continue
+			}
+			r, err := Measure(ctx, f, lines, opts.Runs)
+			if err != nil {
+				return results, &runner.PartError{Day: d.Number, Part: p, Dataset: name, Err: err}
+			}
+			r.Day = d.Number
+			r.Part = p
+			r.Dataset = name
+			results = append(results, r)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,100 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func Test_Measure(t *testing.T) {
+	calls := 0
+	f := func(ctx context.Context, lines []string) (int, error) {
+		calls++
+		b := make([]byte, 1<<20)
+		return len(b) + len(lines), nil
+	}
+	r, err := Measure(context.Background(), f, []string{"a", "b"}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// once more than asked, to watch the heap
+	if calls != 4 {
+		t.Errorf("Measure() called f %d times, want 4", calls)
+	}
+	if r.Answer != 1<<20+2 {
+		t.Errorf("Answer = %d, want %d", r.Answer, 1<<20+2)
+	}
+	if r.Min > r.Mean || r.Mean > r.Max {
+		t.Errorf("durations out of order: min %v mean %v max %v", r.Min, r.Mean, r.Max)
+	}
+	if r.Bytes < 1<<20 {
+		t.Errorf("Bytes = %d, want at least %d", r.Bytes, 1<<20)
+	}
+
+	// nothing else, such as the heap watcher, is counted against f
+	f = func(ctx context.Context, lines []string) (int, error) {
+		return len(lines), nil
+	}
+	if r, err := Measure(context.Background(), f, nil, 2); err != nil || r.Allocs != 0 {
+		t.Errorf("Measure() of a solver that allocates nothing = %d allocs, %v", r.Allocs, err)
+	}
+
+	calls = 0
+	bad := errors.New("bad input")
+	f = func(ctx context.Context, lines []string) (int, error) {
+		calls++
+		return 0, bad
+	}
+	if _, err := Measure(context.Background(), f, nil, 3); !errors.Is(err, bad) || calls != 1 {
+		t.Errorf("Measure() = %v after %d calls, want the solver's error after 1", err, calls)
+	}
+}
+
+func Test_Write(t *testing.T) {
+	results := []Result{
+		{Day: 1, Part: 1, Dataset: "sample", Answer: 142, Runs: 2, Min: 10, Mean: 15, Max: 20, Allocs: 3, Bytes: 400, PeakHeap: 512},
+		{Day: 1, Part: 2, Dataset: "sample", Answer: 281, Runs: 2, Min: 30, Mean: 35, Max: 40, Allocs: 5, Bytes: 600, PeakHeap: 1024},
+	}
+	tests := []struct {
+		format string
+		check  func(t *testing.T, out string)
+	}{
+		{"text", func(t *testing.T, out string) {
+			if n := strings.Count(out, "\n"); n != 3 {
+				t.Errorf("got %d lines, want 3:\n%s", n, out)
+			}
+		}},
+		{"csv", func(t *testing.T, out string) {
+			want := "day,part,dataset,answer,runs,min_ns,mean_ns,max_ns,allocs,bytes,peak_heap_bytes\n" +
+				"1,1,sample,142,2,10,15,20,3,400,512\n" +
+				"1,2,sample,281,2,30,35,40,5,600,1024\n"
+			if out != want {
+				t.Errorf("got\n%s\nwant\n%s", out, want)
+			}
+		}},
+		{"json", func(t *testing.T, out string) {
+			var got []Result
+			if err := json.Unmarshal([]byte(out), &got); err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 2 || got[1] != results[1] {
+				t.Errorf("round trip gave %+v", got)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, tt.format, results); err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, buf.String())
+		})
+	}
+	if err := Write(&bytes.Buffer{}, "xml", results); err == nil {
+		t.Error("Write() with an unknown format should fail")
+	}
+}
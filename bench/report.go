@@ -0,0 +1,75 @@
+package bench
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+)
+
+// Write writes results in the given format: "text", "csv" or "json".
+func Write(w io.Writer, format string, results []Result) error {
+	switch format {
+	case "text":
+		return WriteText(w, results)
+	case "csv":
+		return WriteCSV(w, results)
+	case "json":
+		return WriteJSON(w, results)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+var header = []string{"day", "part", "dataset", "answer", "runs", "min_ns", "mean_ns", "max_ns", "allocs", "bytes", "peak_heap_bytes"}
+
+// WriteText writes results as an aligned table.
+func WriteText(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "day\tpart\tdataset\tanswer\truns\tmin\tmean\tmax\tallocs\tbytes\tpeak heap\t")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%d\t%d\t%v\t%v\t%v\t%d\t%d\t%d\t\n",
+			r.Day, r.Part, r.Dataset, r.Answer, r.Runs, r.Min, r.Mean, r.Max, r.Allocs, r.Bytes, r.PeakHeap)
+	}
+	return tw.Flush()
+}
+
+// WriteCSV writes results as CSV with a header row; durations are in
+// nanoseconds.
+func WriteCSV(w io.Writer, results []Result) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, r := range results {
+		rec := []string{
+			strconv.Itoa(r.Day),
+			strconv.Itoa(r.Part),
+			r.Dataset,
+			strconv.Itoa(r.Answer),
+			strconv.Itoa(r.Runs),
+			strconv.FormatInt(int64(r.Min), 10),
+			strconv.FormatInt(int64(r.Mean), 10),
+			strconv.FormatInt(int64(r.Max), 10),
+			strconv.FormatUint(r.Allocs, 10),
+			strconv.FormatUint(r.Bytes, 10),
+			strconv.FormatUint(r.PeakHeap, 10),
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes results as an indented JSON array.
+func WriteJSON(w io.Writer, results []Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if results == nil {
+		results = []Result{}
+	}
+	return enc.Encode(results)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"os"
+
+	"github.com/kentquirk/aoc2023/bench"
+	"github.com/kentquirk/aoc2023/runner"
+)
+
+func benchCmd(ctx context.Context, args []string) error {
+	var opts bench.Options
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	inputFlag(fs, &opts.Dataset)
+	fs.IntVar(&opts.Part, "part", 0, "part to measure (1 or 2); 0 measures both")
+	fs.IntVar(&opts.Runs, "n", 5, "number of times to run each part")
+	root := rootFlag(fs)
+	fs.Var(&opts.Params, "param", "set one of the day's `key=value` parameters (see aoc list); may be repeated")
+	format := fs.String("format", "text", "output format: text, csv or json")
+	out := fs.String("o", "", "write the report to this file instead of stdout")
+	logFlag(fs)
+	arg, err := splitDay(fs, args, "all")
+	if err != nil {
+		return err
+	}
+	days, err := selectDays(arg)
+	if err != nil {
+		return err
+	}
+	opts.Root = *root
+	if err := oneDay(days, opts.Dataset); err != nil {
+		return err
+	}
+	if err := runner.CheckParams(days, opts.Params); err != nil {
+		return err
+	}
+	results, err := bench.Run(ctx, days, opts)
+	if err != nil {
+		return err
+	}
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return bench.Write(w, *format, results)
+}
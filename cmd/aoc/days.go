@@ -0,0 +1,24 @@
+package main
+
+// Every day registers its solvers with the runner when it is imported.
+import (
+	_ "github.com/kentquirk/aoc2023/day01"
+	_ "github.com/kentquirk/aoc2023/day02"
+	_ "github.com/kentquirk/aoc2023/day03"
+	_ "github.com/kentquirk/aoc2023/day04"
+	_ "github.com/kentquirk/aoc2023/day05"
+	_ "github.com/kentquirk/aoc2023/day06"
+	_ "github.com/kentquirk/aoc2023/day07"
+	_ "github.com/kentquirk/aoc2023/day08"
+	_ "github.com/kentquirk/aoc2023/day09"
+	_ "github.com/kentquirk/aoc2023/day10"
+	_ "github.com/kentquirk/aoc2023/day11"
+	_ "github.com/kentquirk/aoc2023/day12"
+	_ "github.com/kentquirk/aoc2023/day13"
+	_ "github.com/kentquirk/aoc2023/day14"
+	_ "github.com/kentquirk/aoc2023/day15"
+	_ "github.com/kentquirk/aoc2023/day16"
+	_ "github.com/kentquirk/aoc2023/day18"
+	_ "github.com/kentquirk/aoc2023/day19"
+	_ "github.com/kentquirk/aoc2023/day20"
+)
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kentquirk/aoc2023/inputs"
+	"github.com/kentquirk/aoc2023/runner"
+)
+
+// inputCache returns a Cache over root that fetches anything missing using
+// the session in $AOC_SESSION from the server in $AOC_URL, if they're set.
+// A command should make just one, so that its fetches are spaced out.
+func inputCache(root string) *inputs.Cache {
+	c := &inputs.Cache{Dir: root}
+	if session := os.Getenv("AOC_SESSION"); session != "" {
+		f := inputs.NewFetcher(session)
+		f.BaseURL = os.Getenv("AOC_URL")
+		c.Next = f
+	}
+	return c
+}
+
+// isInput reports whether dataset, as runner.Open takes it, is d's real
+// input: "input" by name or as the day's default, or the path of the file
+// it's kept in.
+func isInput(root string, d runner.Day, dataset string) bool {
+	if dataset == "" {
+		dataset = d.Dataset()
+	}
+	if !runner.IsPath(dataset) {
+		return dataset == "input"
+	}
+	a, err := filepath.Abs(dataset)
+	if err != nil {
+		return false
+	}
+	b, err := filepath.Abs(inputs.Path(root, d.Number))
+	return err == nil && a == b
+}
+
+// ensureInput makes sure d's input is on disk if dataset is it, fetching it
+// through c if it's missing and c has a session to fetch it with.
+func ensureInput(ctx context.Context, c *inputs.Cache, d runner.Day, dataset string) error {
+	if c.Next == nil || !isInput(c.Dir, d, dataset) {
+		return nil
+	}
+	_, err := c.Input(ctx, d.Number)
+	return err
+}
+
+func fetchCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("fetch", flag.ExitOnError)
+	root := rootFlag(fs)
+	arg, err := splitDay(fs, args, "")
+	if err != nil {
+		return err
+	}
+	days, err := selectDays(arg)
+	if err != nil {
+		return err
+	}
+	c := inputCache(*root)
+	if c.Next == nil {
+		return errors.New("fetch: set AOC_SESSION to your adventofcode.com session cookie")
+	}
+	for _, d := range days {
+		if _, err := c.Input(ctx, d.Number); err != nil {
+			return err
+		}
+		fmt.Println(inputs.Path(*root, d.Number))
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kentquirk/aoc2023/inputs"
+	"github.com/kentquirk/aoc2023/inputs/inputstest"
+	"github.com/kentquirk/aoc2023/runner"
+)
+
+func Test_ensureInput(t *testing.T) {
+	root := t.TempDir()
+	s := inputstest.NewServer(2023, "cookie", map[int]string{1: "a", 2: "b", 3: "c", 4: "d"})
+	defer s.Close()
+	f := inputs.NewFetcher("cookie")
+	f.BaseURL = s.URL
+	f.Client = s.Client()
+	f.Interval = 50 * time.Millisecond
+	c := &inputs.Cache{Dir: root, Next: f}
+
+	tests := []struct {
+		day     runner.Day
+		dataset string
+		fetch   bool
+	}{
+		{runner.Day{Number: 1}, "input", true},
+		{runner.Day{Number: 2, Default: "input"}, "", true},
+		{runner.Day{Number: 3}, "", false},
+		{runner.Day{Number: 3}, "-", false},
+		{runner.Day{Number: 4}, inputs.Path(root, 4), true},
+	}
+	for _, tt := range tests {
+		if err := ensureInput(context.Background(), c, tt.day, tt.dataset); err != nil {
+			t.Fatal(err)
+		}
+		_, err := os.Stat(inputs.Path(root, tt.day.Number))
+		if fetched := err == nil; fetched != tt.fetch {
+			t.Errorf("day %d with dataset %q: fetched = %v, want %v", tt.day.Number, tt.dataset, fetched, tt.fetch)
+		}
+	}
+
+	// the days share the cache's fetcher, so they wait their turn
+	reqs := s.Requests()
+	if len(reqs) != 3 {
+		t.Fatalf("server saw %d requests, want 3", len(reqs))
+	}
+	for i := 1; i < len(reqs); i++ {
+		if gap := reqs[i].Sub(reqs[i-1]); gap < f.Interval-5*time.Millisecond {
+			t.Errorf("requests %d and %d were only %v apart", i-1, i, gap)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// Command aoc runs the solvers for every day of Advent of Code 2023 from a
+// single binary.
+//
+//	aoc run 12 -part 2 -input input
+//	generate | aoc run 12 -input -
+//	aoc run 12 -input /tmp/big.txt
+//	aoc run all -format ndjson -log debug
+//	aoc run 12 -trace day12.caf
+//	aoc verify 5
+//	aoc bench 14 -n 3 -format csv
+//	aoc new 21
+//	aoc list
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/kentquirk/aoc2023/progress"
+	"github.com/kentquirk/aoc2023/runner"
+	"github.com/kentquirk/aoc2023/trace"
+)
+
+type command struct {
+	name string
+	args string
+	help string
+	run  func(ctx context.Context, args []string) error
+}
+
+var commands []command
+
+// logLevel is the level of the solvers' debug output, which goes to stderr
+// so it never gets mixed up with the answers.
+var logLevel = new(slog.LevelVar)
+
+// logFlag adds the -log flag that sets logLevel, and the -trace flag that
+// turns on trace topics, to fs.
+func logFlag(fs *flag.FlagSet) {
+	fs.TextVar(logLevel, "log", logLevel, "level of the solvers' log output: debug, info, warn or error")
+	fs.Func("trace", "turn on trace `topics`, such as day12.caf or day05,day09.triangle=2", trace.Enable)
+}
+
+// rootFlag adds the -root flag to fs. It defaults to $AOC_ROOT, if that's
+// set, so the binary can be run from anywhere.
+func rootFlag(fs *flag.FlagSet) *string {
+	def := os.Getenv("AOC_ROOT")
+	if def == "" {
+		def = "."
+	}
+	return fs.String("root", def, "directory holding the dayNN directories")
+}
+
+// inputFlag adds the -input flag to fs.
+func inputFlag(fs *flag.FlagSet, p *string) {
+	fs.StringVar(p, "input", "", "`dataset`: a name in the day's data directory, a file path, or - for stdin (default: the day's own default)")
+}
+
+// progressFlag adds the -progress flag, how often a long-running part
+// reports how far it has got, to fs. On a terminal that's a status line
+// redrawn several times a second; otherwise it's a log record every so often.
+func progressFlag(fs *flag.FlagSet) *time.Duration {
+	def := 10 * time.Second
+	if runner.IsTerminal(os.Stderr) {
+		def = 250 * time.Millisecond
+	}
+	return fs.Duration("progress", def, "how often to report the progress of a long-running part (0 turns it off)")
+}
+
+// startProgress returns a context whose solvers' progress is reported every
+// interval, and a function to call once they have all finished.
+func startProgress(ctx context.Context, interval time.Duration) (context.Context, func()) {
+	if interval <= 0 {
+		return ctx, func() {}
+	}
+	var r progress.Reporter = progress.NewLog(nil)
+	if runner.IsTerminal(os.Stderr) {
+		line := progress.NewLine(os.Stderr)
+		// the solvers' log and other output has to clear the status line
+		// first
+		slog.SetDefault(slog.New(runner.NewLogHandler(line.Writer(), logLevel)))
+		ctx = progress.WithWriter(ctx, line.Writer())
+		r = line
+	}
+	m := progress.NewMonitor(r, interval)
+	return m.Context(ctx), m.Close
+}
+
+// oneDay checks that an input from a file or stdin isn't being given to
+// several days at once.
+func oneDay(days []runner.Day, dataset string) error {
+	if len(days) > 1 && (dataset == runner.StdinDataset || runner.IsPath(dataset)) {
+		return fmt.Errorf("%s can only be the input for one day", dataset)
+	}
+	return nil
+}
+
+func init() {
+	commands = []command{
+		{"run", "<day|all> [flags]", "run a day's solvers", runCmd},
+		{"fetch", "<day|all> [flags]", "download missing inputs using $AOC_SESSION", fetchCmd},
+		{"verify", "[day|all] [flags]", "check the solvers against the known answers", verifyCmd},
+		{"bench", "[day|all] [flags]", "time each part and report allocations", benchCmd},
+		{"gentests", "[flags]", "write an answers test into each day", gentestsCmd},
+		{"new", "<day> [flags]", "create dayNN from _template and register it", newCmd},
+		{"list", "", "list the registered days", listCmd},
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: aoc <command> [arguments]")
+	fmt.Fprintln(os.Stderr)
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "    aoc %-28s %s\n", c.name+" "+c.args, c.help)
+	}
+	os.Exit(2)
+}
+
+func main() {
+	slog.SetDefault(slog.New(runner.NewLogHandler(os.Stderr, logLevel)))
+	if len(os.Args) < 2 {
+		usage()
+	}
+	// Ctrl-C cancels the context, so a solver that's taking too long stops
+	// with an error rather than killing the process
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	for _, c := range commands {
+		if c.name == os.Args[1] {
+			if err := c.run(ctx, os.Args[2:]); err != nil {
+				// not log.Fatal, which slog would turn into an INFO record
+				fmt.Fprintln(os.Stderr, "aoc:", err)
+				stop()
+				os.Exit(1)
+			}
+			return
+		}
+	}
+	usage()
+}
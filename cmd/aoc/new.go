@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/kentquirk/aoc2023/runner"
+)
+
+const modulePath = "github.com/kentquirk/aoc2023"
+
+func newCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("new", flag.ExitOnError)
+	root := fs.String("root", ".", "directory holding _template and the dayNN directories")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("new: give exactly one day number")
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(fs.Arg(0), "day"))
+	if err != nil || n < 1 || n > 25 {
+		return fmt.Errorf("new: bad day %q", fs.Arg(0))
+	}
+	d := runner.Day{Number: n}
+	if err := newDay(*root, d); err != nil {
+		return err
+	}
+	fmt.Printf("created %s; rebuild aoc to pick it up\n", filepath.Join(*root, d.Name()))
+	return nil
+}
+
+// newDay copies root/_template into the directory for d, filling in the
+// package name and day number in the Go files, and registers the new package
+// with the aoc command.
+func newDay(root string, d runner.Day) error {
+	dir := filepath.Join(root, d.Name())
+	if _, err := os.Stat(dir); err == nil {
+		return fmt.Errorf("%s already exists", dir)
+	}
+	tmpl := filepath.Join(root, "_template")
+	err := filepath.WalkDir(tmpl, func(path string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(tmpl, path)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(dir, rel)
+		if e.IsDir() {
+			return os.MkdirAll(dst, 0755)
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if filepath.Ext(path) == ".go" {
+			if b, err = expand(rel, b, d); err != nil {
+				return err
+			}
+		}
+		return os.WriteFile(dst, b, 0644)
+	})
+	if err != nil {
+		return err
+	}
+	// every day needs somewhere to put its sample and input, even if the
+	// template doesn't have them
+	for _, name := range []string{"sample", "input"} {
+		path := runner.DataPath(root, d, name)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			return err
+		}
+	}
+	return register(filepath.Join(root, "cmd", "aoc", "days.go"), d)
+}
+
+// expand runs a Go file from the template with d as its data and formats
+// the result.
+func expand(name string, b []byte, d runner.Day) ([]byte, error) {
+	t, err := template.New(name).Parse(string(b))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, d); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// register adds a blank import of d's package to the import block in path,
+// keeping the block in order.
+func register(path string, d runner.Day) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	imp := fmt.Sprintf("\t_ %q", modulePath+"/"+d.Name())
+	lines := strings.Split(string(b), "\n")
+	at := -1
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "\t_ \""+modulePath+"/day") {
+			continue
+		}
+		if line == imp {
+			return nil
+		}
+		if line < imp {
+			at = i + 1
+		} else if at == -1 {
+			at = i
+		}
+	}
+	if at == -1 {
+		return fmt.Errorf("%s: no day imports found", path)
+	}
+	lines = append(lines[:at], append([]string{imp}, lines[at:]...)...)
+	src, err := format.Source([]byte(strings.Join(lines, "\n")))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, src, 0644)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kentquirk/aoc2023/runner"
+)
+
+func Test_newDay(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"_template/main.go":    "package {{.Name}}\n\nvar n = {{.Number}}\n",
+		"_template/main.py":    "print(f\"{x}\")\n",
+		"_template/data/.keep": "",
+		"cmd/aoc/days.go": "package main\n\nimport (\n" +
+			"\t_ \"github.com/kentquirk/aoc2023/day01\"\n" +
+			"\t_ \"github.com/kentquirk/aoc2023/day20\"\n" +
+			")\n",
+	}
+	for name, s := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(s), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d := runner.Day{Number: 9}
+	if err := newDay(root, d); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"day09/main.go":         "package day09\n\nvar n = 9\n",
+		"day09/main.py":         "print(f\"{x}\")\n",
+		"day09/data/sample.txt": "",
+		"day09/data/input.txt":  "",
+	}
+	for name, s := range want {
+		b, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != s {
+			t.Errorf("%s = %q, want %q", name, b, s)
+		}
+	}
+	b, err := os.ReadFile(filepath.Join(root, "cmd/aoc/days.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "day01\"\n\t_ \"github.com/kentquirk/aoc2023/day09\"\n\t_ \"github.com/kentquirk/aoc2023/day20") {
+		t.Errorf("day09 was not registered in order:\n%s", b)
+	}
+
+	if err := newDay(root, d); err == nil {
+		t.Error("newDay() should refuse to overwrite an existing day")
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kentquirk/aoc2023/runner"
+)
+
+// selectDays turns "all", "12" or "day12" into the matching registered days.
+func selectDays(arg string) ([]runner.Day, error) {
+	if arg == "all" {
+		return runner.Days(), nil
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(arg, "day"))
+	if err != nil {
+		return nil, fmt.Errorf("bad day %q", arg)
+	}
+	d, ok := runner.Lookup(n)
+	if !ok {
+		return nil, fmt.Errorf("day %d has no solver", n)
+	}
+	return []runner.Day{d}, nil
+}
+
+// splitDay pulls the day argument off the front of args so that it can come
+// before the flags, as in "aoc run 12 -part 2". If there is no day argument
+// it returns def, or an error if def is empty.
+func splitDay(fs *flag.FlagSet, args []string, def string) (string, error) {
+	day := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		day, args = args[0], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		return "", err
+	}
+	if day == "" {
+		day = fs.Arg(0)
+	}
+	if day == "" {
+		day = def
+	}
+	if day == "" {
+		return "", fmt.Errorf("%s: no day given", fs.Name())
+	}
+	return day, nil
+}
+
+func runCmd(ctx context.Context, args []string) error {
+	var opts runner.Options
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	inputFlag(fs, &opts.Dataset)
+	fs.IntVar(&opts.Part, "part", 0, "part to run (1 or 2); 0 runs both")
+	fs.BoolVar(&opts.Stdin, "stdin", false, "read the input from stdin, as -input - does")
+	root := rootFlag(fs)
+	fs.Var(&opts.Params, "param", "set one of the day's `key=value` parameters (see aoc list); may be repeated")
+	fs.DurationVar(&opts.Timeout, "timeout", 0, "give up on a part after this long, such as 30s (0 means no limit)")
+	timed := fs.Bool("time", false, "report how long each part takes (always included in json and ndjson)")
+	format := fs.String("format", "text", "output format: text, json or ndjson")
+	every := progressFlag(fs)
+	logFlag(fs)
+	arg, err := splitDay(fs, args, "")
+	if err != nil {
+		return err
+	}
+	days, err := selectDays(arg)
+	if err != nil {
+		return err
+	}
+	opts.Root = *root
+	if opts.Stdin {
+		opts.Dataset = runner.StdinDataset
+	}
+	if err := oneDay(days, opts.Dataset); err != nil {
+		return err
+	}
+	if err := runner.CheckParams(days, opts.Params); err != nil {
+		return err
+	}
+	rw, err := runner.NewResultWriter(os.Stdout, *format, *timed)
+	if err != nil {
+		return err
+	}
+	ctx, stop := startProgress(ctx, *every)
+	defer stop()
+	cache := inputCache(opts.Root)
+	failed := 0
+	for _, d := range days {
+		if err := ensureInput(ctx, cache, d, opts.Dataset); err != nil {
+			return err
+		}
+		if err := runner.Run(ctx, rw, d, opts); err != nil {
+			// a solver that fails doesn't stop the other days
+			var pe *runner.PartError
+			if !errors.As(err, &pe) || len(days) == 1 {
+				return err
+			}
+			fmt.Fprintln(os.Stderr, "aoc:", err)
+			failed++
+		}
+	}
+	if err := rw.Close(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d days failed", failed, len(days))
+	}
+	return nil
+}
+
+func listCmd(ctx context.Context, args []string) error {
+	for _, d := range runner.Days() {
+		fmt.Printf("%s (default dataset %q)\n", d.Name(), d.Dataset())
+		names := make([]string, 0, len(d.Params))
+		for name := range d.Params {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("    -param %s=...: %s\n", name, d.Params[name])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/kentquirk/aoc2023/runner"
+	"github.com/kentquirk/aoc2023/verify"
+)
+
+func verifyCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	short := fs.Bool("short", false, "skip the full inputs")
+	quiet := fs.Bool("q", false, "only report failures")
+	root := rootFlag(fs)
+	logFlag(fs)
+	arg, err := splitDay(fs, args, "all")
+	if err != nil {
+		return err
+	}
+	days, err := selectDays(arg)
+	if err != nil {
+		return err
+	}
+	var skip func(string) bool
+	if *short {
+		skip = func(dataset string) bool { return dataset == "input" }
+	}
+	failures := 0
+	for _, d := range days {
+		results, err := verify.Check(ctx, *root, d, skip)
+		for _, r := range results {
+			if !r.OK() {
+				failures++
+			}
+			if !r.OK() || !*quiet {
+				fmt.Println(r)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if failures > 0 {
+		return fmt.Errorf("%d answers did not match", failures)
+	}
+	return nil
+}
+
+//go:generate go run . gentests -root ../..
+
+var answersTest = template.Must(template.New("answers").Parse(`// Code generated by "aoc gentests"; DO NOT EDIT.
+
+package {{.Name}}
+
+import (
+	"testing"
+
+	"github.com/kentquirk/aoc2023/verify"
+)
+
+func TestAnswers(t *testing.T) {
+	verify.Test(t, "..", {{.Number}})
+}
+`))
+
+// gentestsCmd writes answers_test.go into every day that has an answers file.
+func gentestsCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("gentests", flag.ExitOnError)
+	root := rootFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	for _, d := range runner.Days() {
+		if _, err := os.Stat(verify.Path(*root, d)); err != nil {
+			continue
+		}
+		f, err := os.Create(filepath.Join(*root, d.Name(), "answers_test.go"))
+		if err != nil {
+			return err
+		}
+		err = answersTest.Execute(f, d)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
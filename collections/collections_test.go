@@ -0,0 +1,129 @@
+package collections
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_Set(t *testing.T) {
+	a := NewSet(1, 2, 3, 4)
+	b := NewSet(3, 4, 5)
+	tests := []struct {
+		name string
+		got  Set[int]
+		want []int
+	}{
+		{"union", a.Union(b), []int{1, 2, 3, 4, 5}},
+		{"intersect", a.Intersect(b), []int{3, 4}},
+		{"difference", a.Difference(b), []int{1, 2}},
+		{"other difference", b.Difference(a), []int{5}},
+		{"empty", NewSet[int](), []int{}},
+		{"nil union", Set[int](nil).Union(b), []int{3, 4, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sorted(tt.got); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if a.Len() != 4 || !a.Contains(1) || a.Contains(5) {
+		t.Errorf("Union and friends changed a: %v", Sorted(a))
+	}
+	a.Remove(1)
+	a.Remove(99)
+	if a.Contains(1) || a.Len() != 3 {
+		t.Errorf("Remove() left %v", Sorted(a))
+	}
+	n := 0
+	a.Iter(func(int) bool {
+		n++
+		return n < 2
+	})
+	if n != 2 {
+		t.Errorf("Iter() kept going after yield returned false: %d calls", n)
+	}
+}
+
+func Test_Counter(t *testing.T) {
+	c := make(Counter[rune])
+	for _, r := range "32T3K" {
+		c.Add(r)
+	}
+	tests := []struct {
+		name string
+		got  int
+		want int
+	}{
+		{"count", c['3'], 2},
+		{"unseen", c['A'], 0},
+		{"len", c.Len(), 4},
+		{"total", c.Total(), 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %d, want %d", tt.got, tt.want)
+			}
+		})
+	}
+
+	if got, want := MostCommon(c), []rune("32KT"); !reflect.DeepEqual(got, want) {
+		t.Errorf("MostCommon() = %q, want %q", got, want)
+	}
+	c.AddN('3', -2)
+	if _, ok := c['3']; ok {
+		t.Error("AddN() down to zero should remove the item")
+	}
+}
+
+func Test_PriorityQueue(t *testing.T) {
+	type node struct {
+		name string
+		cost int
+	}
+	q := NewPriorityQueue(func(a, b node) bool { return a.cost < b.cost })
+	for _, n := range []node{{"c", 3}, {"a", 1}, {"e", 5}, {"b", 2}, {"d", 4}} {
+		q.Push(n)
+	}
+	if q.Peek().name != "a" {
+		t.Errorf("Peek() = %v", q.Peek())
+	}
+	var got string
+	for q.Len() > 0 {
+		got += q.Pop().name
+	}
+	if got != "abcde" {
+		t.Errorf("popped %q, want %q", got, "abcde")
+	}
+}
+
+func Test_Deque(t *testing.T) {
+	var d Deque[int]
+	// enough to wrap around and grow a few times
+	for i := 0; i < 100; i++ {
+		d.PushBack(i)
+		d.PushFront(-i)
+	}
+	if d.Len() != 200 || d.Front() != -99 || d.Back() != 99 {
+		t.Fatalf("Len() = %d, Front() = %d, Back() = %d", d.Len(), d.Front(), d.Back())
+	}
+	for i := 99; i >= 0; i-- {
+		if v := d.PopBack(); v != i {
+			t.Fatalf("PopBack() = %d, want %d", v, i)
+		}
+		if v := d.PopFront(); v != -i {
+			t.Fatalf("PopFront() = %d, want %d", v, -i)
+		}
+	}
+	if d.Len() != 0 {
+		t.Errorf("Len() = %d after popping everything", d.Len())
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("PopFront() of an empty deque should panic")
+		}
+	}()
+	d.PopFront()
+}
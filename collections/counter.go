@@ -0,0 +1,61 @@
+package collections
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Counter counts how many times each T has been seen; it's a multiset.
+// Reading the count of something never seen gives 0.
+type Counter[T comparable] map[T]int
+
+// Add counts i once more.
+func (c Counter[T]) Add(i T) {
+	c[i]++
+}
+
+// AddN counts i n more times. If that brings its count to zero or below, it
+// is removed.
+func (c Counter[T]) AddN(i T, n int) {
+	c[i] += n
+	if c[i] <= 0 {
+		delete(c, i)
+	}
+}
+
+// AddAll counts each of i once more.
+func (c Counter[T]) AddAll(i ...T) {
+	for _, v := range i {
+		c.Add(v)
+	}
+}
+
+// Len returns the number of different items counted.
+func (c Counter[T]) Len() int {
+	return len(c)
+}
+
+// Total returns the sum of all the counts.
+func (c Counter[T]) Total() int {
+	t := 0
+	for _, n := range c {
+		t += n
+	}
+	return t
+}
+
+// MostCommon returns the items from the highest count to the lowest, with
+// ties in ascending order.
+func MostCommon[T cmp.Ordered](c Counter[T]) []T {
+	vs := make([]T, 0, len(c))
+	for v := range c {
+		vs = append(vs, v)
+	}
+	slices.SortFunc(vs, func(a, b T) int {
+		if c[a] != c[b] {
+			return c[b] - c[a]
+		}
+		return cmp.Compare(a, b)
+	})
+	return vs
+}
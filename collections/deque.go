@@ -0,0 +1,91 @@
+package collections
+
+// Deque is a double-ended queue in a ring buffer that grows as needed. The
+// zero Deque is empty and ready to use.
+type Deque[T any] struct {
+	buf   []T
+	head  int
+	count int
+}
+
+// Len returns the number of items in the deque.
+func (d *Deque[T]) Len() int {
+	return d.count
+}
+
+func (d *Deque[T]) grow() {
+	if d.count < len(d.buf) {
+		return
+	}
+	n := 2 * len(d.buf)
+	if n == 0 {
+		n = 16
+	}
+	buf := make([]T, n)
+	for i := 0; i < d.count; i++ {
+		buf[i] = d.buf[(d.head+i)%len(d.buf)]
+	}
+	d.buf = buf
+	d.head = 0
+}
+
+// PushBack adds v at the back.
+func (d *Deque[T]) PushBack(v T) {
+	d.grow()
+	d.buf[(d.head+d.count)%len(d.buf)] = v
+	d.count++
+}
+
+// PushFront adds v at the front.
+func (d *Deque[T]) PushFront(v T) {
+	d.grow()
+	d.head = (d.head + len(d.buf) - 1) % len(d.buf)
+	d.buf[d.head] = v
+	d.count++
+}
+
+// PopFront removes and returns the item at the front. It panics if the
+// deque is empty.
+func (d *Deque[T]) PopFront() T {
+	if d.count == 0 {
+		panic("collections: PopFront of empty Deque")
+	}
+	var zero T
+	v := d.buf[d.head]
+	d.buf[d.head] = zero
+	d.head = (d.head + 1) % len(d.buf)
+	d.count--
+	return v
+}
+
+// PopBack removes and returns the item at the back. It panics if the deque
+// is empty.
+func (d *Deque[T]) PopBack() T {
+	if d.count == 0 {
+		panic("collections: PopBack of empty Deque")
+	}
+	var zero T
+	i := (d.head + d.count - 1) % len(d.buf)
+	v := d.buf[i]
+	d.buf[i] = zero
+	d.count--
+	return v
+}
+
+// Front returns the item at the front without removing it. It panics if
+// the deque is empty.
+func (d *Deque[T]) Front() T {
+	if d.count == 0 {
+		panic("collections: Front of empty Deque")
+	}
+	return d.buf[d.head]
+}
+
+// Back returns the item at the back without removing it. It panics if the
+// deque is empty.
+func (d *Deque[T]) Back() T {
+	if d.count == 0 {
+		panic("collections: Back of empty Deque")
+	}
+	return d.buf[(d.head+d.count-1)%len(d.buf)]
+}
@@ -0,0 +1,59 @@
+package collections
+
+import "container/heap"
+
+// PriorityQueue hands back its items smallest first, as decided by the less
+// function it was made with.
+type PriorityQueue[T any] struct {
+	h pqheap[T]
+}
+
+// NewPriorityQueue returns an empty queue ordered by less.
+func NewPriorityQueue[T any](less func(a, b T) bool) *PriorityQueue[T] {
+	return &PriorityQueue[T]{h: pqheap[T]{less: less}}
+}
+
+// Push adds v to the queue.
+func (q *PriorityQueue[T]) Push(v T) {
+	heap.Push(&q.h, v)
+}
+
+// Pop removes and returns the smallest item. It panics if the queue is
+// empty.
+func (q *PriorityQueue[T]) Pop() T {
+	return heap.Pop(&q.h).(T)
+}
+
+// Peek returns the smallest item without removing it. It panics if the
+// queue is empty.
+func (q *PriorityQueue[T]) Peek() T {
+	return q.h.items[0]
+}
+
+// Len returns the number of items in the queue.
+func (q *PriorityQueue[T]) Len() int {
+	return len(q.h.items)
+}
+
+// pqheap adapts a slice to container/heap.
+type pqheap[T any] struct {
+	items []T
+	less  func(a, b T) bool
+}
+
+func (h pqheap[T]) Len() int           { return len(h.items) }
+func (h pqheap[T]) Less(i, j int) bool { return h.less(h.items[i], h.items[j]) }
+func (h pqheap[T]) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *pqheap[T]) Push(x any) {
+	h.items = append(h.items, x.(T))
+}
+
+func (h *pqheap[T]) Pop() any {
+	n := len(h.items) - 1
+	v := h.items[n]
+	var zero T
+	h.items[n] = zero
+	h.items = h.items[:n]
+	return v
+}
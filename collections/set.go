@@ -0,0 +1,116 @@
+// Package collections has the small container types the puzzles keep
+// needing: a set, a counter, a priority queue and a deque.
+package collections
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Set is an unordered set of T. The zero Set is nil and can be read but not
+// added to; make one with NewSet or make.
+type Set[T comparable] map[T]struct{}
+
+// NewSet returns a set holding items.
+func NewSet[T comparable](items ...T) Set[T] {
+	s := make(Set[T], len(items))
+	s.AddAll(items...)
+	return s
+}
+
+// Add adds i to the set.
+func (s Set[T]) Add(i T) {
+	s[i] = struct{}{}
+}
+
+// AddAll adds each of i to the set.
+func (s Set[T]) AddAll(i ...T) {
+	for _, v := range i {
+		s.Add(v)
+	}
+}
+
+// Remove takes i out of the set, if it's there.
+func (s Set[T]) Remove(i T) {
+	delete(s, i)
+}
+
+// Contains reports whether i is in the set.
+func (s Set[T]) Contains(i T) bool {
+	_, ok := s[i]
+	return ok
+}
+
+// Len returns the number of items in the set.
+func (s Set[T]) Len() int {
+	return len(s)
+}
+
+// Clone returns a copy of the set.
+func (s Set[T]) Clone() Set[T] {
+	c := make(Set[T], len(s))
+	for v := range s {
+		c.Add(v)
+	}
+	return c
+}
+
+// Union returns a new set of the items in either s or o.
+func (s Set[T]) Union(o Set[T]) Set[T] {
+	u := s.Clone()
+	for v := range o {
+		u.Add(v)
+	}
+	return u
+}
+
+// Intersect returns a new set of the items in both s and o.
+func (s Set[T]) Intersect(o Set[T]) Set[T] {
+	if len(o) < len(s) {
+		s, o = o, s
+	}
+	n := make(Set[T])
+	for v := range s {
+		if o.Contains(v) {
+			n.Add(v)
+		}
+	}
+	return n
+}
+
+// Difference returns a new set of the items in s but not in o.
+func (s Set[T]) Difference(o Set[T]) Set[T] {
+	d := make(Set[T])
+	for v := range s {
+		if !o.Contains(v) {
+			d.Add(v)
+		}
+	}
+	return d
+}
+
+// Iter calls yield for each item, in no particular order, until it returns
+// false.
+func (s Set[T]) Iter(yield func(T) bool) {
+	for v := range s {
+		if !yield(v) {
+			return
+		}
+	}
+}
+
+// Values returns the items in no particular order.
+func (s Set[T]) Values() []T {
+	vs := make([]T, 0, len(s))
+	for v := range s {
+		vs = append(vs, v)
+	}
+	return vs
+}
+
+// Sorted returns the items of s in ascending order.
+func Sorted[T cmp.Ordered](s Set[T]) []T {
+	vs := s.Values()
+	slices.Sort(vs)
+	return vs
+}
@@ -0,0 +1,153 @@
+// Package cycle finds where a simulation starts repeating itself, so that
+// the state after a billion steps can be worked out from the first few
+// hundred.
+//
+// A simulation whose step function returns a new state can use Floyd or
+// Brent, which keep only a couple of states around. One that changes its
+// state in place, which is most of them, feeds a Detector the key of each
+// state in turn and keeps a history of whatever it wants to know about each
+// step; Extrapolate and Sum then answer for step N.
+//
+// States are compared by key: the state itself if it is small and
+// comparable, or a hash or string of it otherwise.
+package cycle
+
+// Cycle describes a sequence of states that, after Start steps, repeats
+// every Period steps. Step 0 is the initial state.
+type Cycle struct {
+	Start  int
+	Period int
+}
+
+// Index returns the earliest step whose state is the same as step n's.
+func (c Cycle) Index(n int) int {
+	if n < c.Start || c.Period == 0 {
+		return n
+	}
+	return c.Start + (n-c.Start)%c.Period
+}
+
+// Floyd finds the cycle in x0, f(x0), f(f(x0)), ... with Floyd's tortoise
+// and hare. f must return a new state, not change its argument.
+func Floyd[T any, K comparable](x0 T, f func(T) T, key func(T) K) Cycle {
+	tortoise, hare := f(x0), f(f(x0))
+	for key(tortoise) != key(hare) {
+		tortoise, hare = f(tortoise), f(f(hare))
+	}
+
+	start := 0
+	tortoise = x0
+	for key(tortoise) != key(hare) {
+		tortoise, hare = f(tortoise), f(hare)
+		start++
+	}
+
+	period := 1
+	hare = f(tortoise)
+	for key(tortoise) != key(hare) {
+		hare = f(hare)
+		period++
+	}
+	return Cycle{Start: start, Period: period}
+}
+
+// Brent finds the same cycle as Floyd using Brent's algorithm, which calls
+// f fewer times.
+func Brent[T any, K comparable](x0 T, f func(T) T, key func(T) K) Cycle {
+	power, period := 1, 1
+	tortoise, hare := x0, f(x0)
+	for key(tortoise) != key(hare) {
+		if power == period {
+			tortoise = hare
+			power *= 2
+			period = 0
+		}
+		hare = f(hare)
+		period++
+	}
+
+	tortoise, hare = x0, x0
+	for i := 0; i < period; i++ {
+		hare = f(hare)
+	}
+	start := 0
+	for key(tortoise) != key(hare) {
+		tortoise, hare = f(tortoise), f(hare)
+		start++
+	}
+	return Cycle{Start: start, Period: period}
+}
+
+// Nth returns the state after n steps from x0, taking no more steps than it
+// needs to given the cycle c.
+func Nth[T any](x0 T, f func(T) T, c Cycle, n int) T {
+	x := x0
+	for i := c.Index(n); i > 0; i-- {
+		x = f(x)
+	}
+	return x
+}
+
+// Detector finds a cycle by remembering the key of every state it has seen.
+// The zero Detector is ready to use.
+type Detector[K comparable] struct {
+	seen  map[K]int
+	steps int
+}
+
+// Add records the key of the next state, starting with step 0. Once a key
+// turns up that has been seen before, it returns the cycle and true.
+func (d *Detector[K]) Add(k K) (Cycle, bool) {
+	if d.seen == nil {
+		d.seen = make(map[K]int)
+	}
+	step := d.steps
+	d.steps++
+	if first, ok := d.seen[k]; ok {
+		return Cycle{Start: first, Period: step - first}, true
+	}
+	d.seen[k] = step
+	return Cycle{}, false
+}
+
+// Steps returns how many states have been added.
+func (d *Detector[K]) Steps() int {
+	return d.steps
+}
+
+// Extrapolate returns what history, which holds something about each step
+// up to the end of the first time round the cycle, says about step n.
+func Extrapolate[V any](c Cycle, history []V, n int) V {
+	return history[c.Index(n)]
+}
+
+type number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// Sum returns the total of history over steps 0 to n-1, where history is as
+// for Extrapolate. If history already covers n steps, c isn't needed.
+func Sum[V number](c Cycle, history []V, n int) V {
+	var total V
+	if n <= len(history) {
+		for _, v := range history[:n] {
+			total += v
+		}
+		return total
+	}
+	for _, v := range history[:c.Start] {
+		total += v
+	}
+	var loop V
+	for _, v := range history[c.Start : c.Start+c.Period] {
+		loop += v
+	}
+	rest := n - c.Start
+	total += loop * V(rest/c.Period)
+	for _, v := range history[c.Start : c.Start+rest%c.Period] {
+		total += v
+	}
+	return total
+}
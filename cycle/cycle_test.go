@@ -0,0 +1,86 @@
+package cycle
+
+import "testing"
+
+// rho steps through 0, 1, ... start-1 and then round start ... start+period-1.
+func rho(start, period int) func(int) int {
+	return func(x int) int {
+		if x+1 == start+period {
+			return start
+		}
+		return x + 1
+	}
+}
+
+func id(x int) int { return x }
+
+func Test_Find(t *testing.T) {
+	tests := []struct {
+		name   string
+		start  int
+		period int
+	}{
+		{"fixed point", 0, 1},
+		{"loop from the start", 0, 7},
+		{"tail then loop", 5, 3},
+		{"long tail", 100, 1},
+		{"long loop", 3, 250},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := Cycle{Start: tt.start, Period: tt.period}
+			f := rho(tt.start, tt.period)
+			if got := Floyd(0, f, id); got != want {
+				t.Errorf("Floyd() = %+v, want %+v", got, want)
+			}
+			if got := Brent(0, f, id); got != want {
+				t.Errorf("Brent() = %+v, want %+v", got, want)
+			}
+			var d Detector[int]
+			x := 0
+			for {
+				if got, ok := d.Add(x); ok {
+					if got != want {
+						t.Errorf("Detector found %+v, want %+v", got, want)
+					}
+					break
+				}
+				x = f(x)
+			}
+			if d.Steps() != tt.start+tt.period+1 {
+				t.Errorf("Detector took %d steps", d.Steps())
+			}
+		})
+	}
+}
+
+func Test_Extrapolate(t *testing.T) {
+	// 10 20 | 1 2 3 | 1 2 3 | ...
+	c := Cycle{Start: 2, Period: 3}
+	history := []int{10, 20, 1, 2, 3}
+	tests := []struct {
+		name    string
+		n       int
+		wantAt  int
+		wantSum int
+	}{
+		{"in the tail", 1, 20, 10},
+		{"first time round", 4, 3, 33},
+		{"second time round", 6, 2, 37},
+		{"a billion", 1_000_000_000, 3, 30 + 6*333_333_332 + 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Extrapolate(c, history, tt.n); got != tt.wantAt {
+				t.Errorf("Extrapolate() = %d, want %d", got, tt.wantAt)
+			}
+			if got := Sum(c, history, tt.n); got != tt.wantSum {
+				t.Errorf("Sum() = %d, want %d", got, tt.wantSum)
+			}
+			// the states of rho are numbered by the step they first appear at
+			if got := Nth(0, rho(2, 3), c, tt.n); got != c.Index(tt.n) {
+				t.Errorf("Nth() = %d, want %d", got, c.Index(tt.n))
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package day01
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ANSI colors for the explanation: the first token, the last, and any
+// letters that are in both, as the "o" of "twone" is.
+const (
+	colorFirst = "\x1b[32m"
+	colorLast  = "\x1b[36m"
+	colorBoth  = "\x1b[33m"
+	colorReset = "\x1b[0m"
+)
+
+// explainer writes out how calibrate got each line's value, for tracking
+// down which line a disagreement comes from. With color, the first and last
+// tokens are highlighted in the line itself; without it, they're marked on
+// the line below with ^ for the first, $ for the last and * for both.
+type explainer struct {
+	w     io.Writer
+	color bool
+}
+
+// line explains the line numbered n, whose first and last tokens are given
+// if ok.
+func (e *explainer) line(n int, line string, first, last token, ok bool) {
+	if !ok {
+		fmt.Fprintf(e.w, "%5d  %s  no digits\n", n, line)
+		return
+	}
+	value := leading(first.Value)*10 + trailing(last.Value)
+	if e.color {
+		fmt.Fprintf(e.w, "%5d  %s  %d\n", n, highlight(line, first, last), value)
+		return
+	}
+	fmt.Fprintf(e.w, "%5d  %s  %d\n", n, line, value)
+	fmt.Fprintf(e.w, "%5s  %s\n", "", strings.TrimRight(marks(line, first, last), " "))
+}
+
+// summary lists the lines that had no digits at all.
+func (e *explainer) summary(missing []int) {
+	if len(missing) == 0 {
+		return
+	}
+	nums := make([]string, len(missing))
+	for i, n := range missing {
+		nums[i] = fmt.Sprint(n)
+	}
+	plural := "s"
+	if len(missing) == 1 {
+		plural = ""
+	}
+	fmt.Fprintf(e.w, "%d line%s had no digits: %s\n", len(missing), plural, strings.Join(nums, ", "))
+}
+
+// style returns which of the tokens byte i of a line is in: 0 for neither,
+// 1 for the first, 2 for the last or 3 for both.
+func style(i int, first, last token) int {
+	s := 0
+	if i >= first.Start && i < first.End {
+		s |= 1
+	}
+	if i >= last.Start && i < last.End {
+		s |= 2
+	}
+	return s
+}
+
+func highlight(line string, first, last token) string {
+	colors := []string{"", colorFirst, colorLast, colorBoth}
+	var sb strings.Builder
+	cur := 0
+	for i := 0; i < len(line); i++ {
+		if s := style(i, first, last); s != cur {
+			if cur != 0 {
+				sb.WriteString(colorReset)
+			}
+			sb.WriteString(colors[s])
+			cur = s
+		}
+		sb.WriteByte(line[i])
+	}
+	if cur != 0 {
+		sb.WriteString(colorReset)
+	}
+	return sb.String()
+}
+
+// marks returns the line of markers that goes under line. A multibyte
+// character gets a single marker, so they line up.
+func marks(line string, first, last token) string {
+	chars := " ^$*"
+	var sb strings.Builder
+	for i := range line {
+		sb.WriteByte(chars[style(i, first, last)])
+	}
+	return sb.String()
+}
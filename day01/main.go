@@ -1,89 +1,128 @@
-package main
+package day01
 
 import (
+	"context"
 	"fmt"
-	"io"
-	"log"
+	"log/slog"
 	"os"
-	"regexp"
+	"strconv"
 	"strings"
+
+	"github.com/kentquirk/aoc2023/progress"
+	"github.com/kentquirk/aoc2023/runner"
+	"github.com/kentquirk/aoc2023/trace"
 )
 
-func part1(lines []string) int {
-	pat := regexp.MustCompile(`[\d]`)
+// numerals is part1's vocabulary; part2 adds words to it.
+var numerals = vocabulary{"0": 0, "1": 1, "2": 2, "3": 3, "4": 4, "5": 5, "6": 6, "7": 7, "8": 8, "9": 9}
 
-	total := 0
-	for _, line := range lines {
-		m := pat.FindAllString(line, -1)
-		if m == nil {
-			fmt.Println("no match - ", line)
-			continue
-		}
-		n1 := m[0][0] - '0'
-		n2 := m[len(m)-1][0] - '0'
-		fmt.Println(n1, n2)
-		total += int(n1*10 + n2)
-	}
+var (
+	part1Scanner = newScanner(numerals)
+	part2Scanner = newScanner(merge(numerals, mustLoad("english")))
+)
 
-	return total
+var tline = trace.New("day01", "line")
+
+func mustLoad(name string) vocabulary {
+	v, err := loadVocabulary(name)
+	if err != nil {
+		panic(err)
+	}
+	return v
 }
 
-var digits []string = []string{"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine"}
+// merge returns a vocabulary with the words of all the vocabs.
+func merge(vocabs ...vocabulary) vocabulary {
+	all := make(vocabulary)
+	for _, v := range vocabs {
+		for w, n := range v {
+			all[w] = n
+		}
+	}
+	return all
+}
 
-func part2(lines []string) int {
+// calibrate adds up the calibration values of the lines: the first digit
+// s finds in each followed by the last. If e isn't nil, it explains each
+// one.
+func calibrate(s *scanner, lines []string, e *explainer) int {
 	total := 0
-	for _, line := range lines {
-		firstix := 999999
-		lastix := 0
-		firstval := 0
-		lastval := 0
-		// fmt.Println("---", line)
-		pat := regexp.MustCompile(`[\d]`)
-		m := pat.FindAllStringIndex(line, -1)
-		if m != nil {
-			firstix = m[0][0]
-			firstval = int(line[m[0][0]] - '0')
-			lastix = m[len(m)-1][1]
-			lastval = int(line[m[len(m)-1][0]] - '0')
+	var missing []int
+	for i, line := range lines {
+		first, last, ok := s.firstLast(line)
+		if e != nil {
+			e.line(i+1, line, first, last, ok)
 		}
-		for d, w := range digits {
-			pat = regexp.MustCompile(w)
-			m = pat.FindAllStringIndex(line, -1)
-			// fmt.Println(d, w, m)
-			if m != nil {
-				ix1 := m[0][0]
-				if ix1 < firstix {
-					firstix = ix1
-					firstval = d
-				}
-				ix2 := m[len(m)-1][1]
-				if ix2 > lastix {
-					lastix = ix2
-					lastval = d
-				}
-			}
+		if !ok {
+			missing = append(missing, i+1)
+			continue
 		}
-		// fmt.Println(firstval, lastval, line)
-		total += int(firstval*10 + lastval)
+		tline.Log("digits", "first", first.Value, "last", last.Value, "line", line)
+		total += leading(first.Value)*10 + trailing(last.Value)
+	}
+	if e != nil {
+		e.summary(missing)
+	} else if len(missing) > 0 {
+		slog.Debug("no digits", "count", len(missing), "lines", missing)
 	}
-
 	return total
 }
 
-func main() {
-	args := os.Args[1:]
-	name := "sample2"
-	if len(args) > 0 {
-		name = args[0]
+// explainFor returns an explainer if the explain parameter asks for one, or
+// nil. It writes to stderr, by way of the progress line if there is one.
+func explainFor(ctx context.Context, part int) (*explainer, error) {
+	v, ok := runner.Param(ctx, "explain")
+	if !ok {
+		return nil, nil
 	}
-	f, err := os.Open(fmt.Sprintf("./data/%s.txt", name))
+	on, err := strconv.ParseBool(v)
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("explain=%s: want true or false", v)
 	}
-	b, err := io.ReadAll(f)
+	if !on {
+		return nil, nil
+	}
+	w := progress.Writer(ctx)
+	fmt.Fprintf(w, "part %d:\n", part)
+	color := runner.IsTerminal(os.Stderr) && os.Getenv("NO_COLOR") == ""
+	return &explainer{w: w, color: color}, nil
+}
+
+func part1(ctx context.Context, lines []string) (int, error) {
+	e, err := explainFor(ctx, 1)
 	if err != nil {
-		log.Fatal(err)
+		return 0, err
 	}
-	lines := strings.Split(string(b), "\n")
-	fmt.Println(part2(lines))
+	return calibrate(part1Scanner, lines, e), nil
+}
+
+// part2 counts spelled-out digits too, and they can overlap: "eightwo" is
+// 82. The words are English unless the vocab parameter says otherwise.
+func part2(ctx context.Context, lines []string) (int, error) {
+	e, err := explainFor(ctx, 2)
+	if err != nil {
+		return 0, err
+	}
+	s := part2Scanner
+	if name, ok := runner.Param(ctx, "vocab"); ok {
+		v, err := loadVocabulary(name)
+		if err != nil {
+			return 0, err
+		}
+		s = newScanner(merge(numerals, v))
+	}
+	return calibrate(s, lines, e), nil
+}
+
+func init() {
+	runner.Register(runner.Day{
+		Number:  1,
+		Part1:   part1,
+		Part2:   part2,
+		Default: "sample2",
+		Params: map[string]string{
+			"explain": "true to show how each line's value was found",
+			"vocab":   "part 2's words for the digits: " + strings.Join(vocabularies(), ", ") + " (the default is english), or a file of word value lines",
+		},
+	})
 }
@@ -0,0 +1,278 @@
+package day01
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/kentquirk/aoc2023/progress"
+	"github.com/kentquirk/aoc2023/runner"
+)
+
+func Test_tokens(t *testing.T) {
+	tests := []struct {
+		line string
+		want []token
+	}{
+		{"", nil},
+		{"abc", nil},
+		{"a1b22", []token{{1, 2, 1}, {3, 4, 2}, {4, 5, 2}}},
+		{"eightwo", []token{{0, 5, 8}, {4, 7, 2}}},
+		{"twone3", []token{{0, 3, 2}, {2, 5, 1}, {5, 6, 3}}},
+		{"oneight", []token{{0, 3, 1}, {2, 7, 8}}},
+		{"ssseven", []token{{2, 7, 7}}},
+		{"nineight", []token{{0, 4, 9}, {3, 8, 8}}},
+		{"sevenine", []token{{0, 5, 7}, {4, 8, 9}}},
+		{"zerone", []token{{0, 4, 0}, {3, 6, 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			if got := part2Scanner.tokens(tt.line); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokens() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_firstLast(t *testing.T) {
+	// a word inside a longer one, to check which wins a tie
+	s := newScanner(map[string]int{"ten": 10, "tent": 7, "en": 5, "nt": 3})
+	tests := []struct {
+		line        string
+		first, last token
+		ok          bool
+	}{
+		{"xyz", token{}, token{}, false},
+		{"tent", token{0, 4, 7}, token{0, 4, 7}, true},
+		{"xten", token{1, 4, 10}, token{1, 4, 10}, true},
+		{"enxnt", token{0, 2, 5}, token{3, 5, 3}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			first, last, ok := s.firstLast(tt.line)
+			if first != tt.first || last != tt.last || ok != tt.ok {
+				t.Errorf("firstLast() = %v, %v, %v, want %v, %v, %v", first, last, ok, tt.first, tt.last, tt.ok)
+			}
+		})
+	}
+}
+
+func Test_scanStops(t *testing.T) {
+	n := 0
+	part2Scanner.scan("one2three4", func(token) bool {
+		n++
+		return n < 2
+	})
+	if n != 2 {
+		t.Errorf("scan() yielded %d tokens after being told to stop at 2", n)
+	}
+}
+
+func Test_vocabularies(t *testing.T) {
+	tests := []struct {
+		vocab string
+		lines []string
+		want  int
+	}{
+		{"english", []string{"two1nine", "eightwothree", "zoneight234", "7pqrstsixteen"}, 29 + 83 + 14 + 76},
+		{"french", []string{"deux1neuf", "huitroisix", "aucune3", "zéro"}, 29 + 86 + 13 + 0},
+		{"reversed", []string{"enin1owt", "eerhtowthgie", "4ruofeno"}, 92 + 38 + 41},
+		{"teens", []string{"twelve", "fourteen6six", "seventeen", "xninetyeight3", "ten"}, 12 + 16 + 17 + 93 + 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.vocab, func(t *testing.T) {
+			ctx := runner.WithParams(context.Background(), runner.Params{"vocab": tt.vocab})
+			got, err := part2(ctx, tt.lines)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("part2() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+	if names := vocabularies(); !reflect.DeepEqual(names, []string{"english", "french", "reversed", "teens"}) {
+		t.Errorf("vocabularies() = %v", names)
+	}
+}
+
+func Test_loadVocabulary(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "roman.txt")
+	if err := os.WriteFile(file, []byte("# Roman numerals\nI 1\nII 2\n\nIII 3\nIV 4\nV 5\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	v, err := loadVocabulary(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(v) != 5 || v["IV"] != 4 {
+		t.Errorf("loadVocabulary() = %v", v)
+	}
+	if got := calibrate(newScanner(v), []string{"XIV", "VIII"}, nil); got != 44+53 {
+		t.Errorf("calibrate() = %d, want %d", got, 44+53)
+	}
+
+	tests := []struct {
+		name    string
+		lines   []string
+		wantErr string
+	}{
+		{"no value", []string{"one"}, `line 1, col 1: want 2 fields, found 1 in "one"`},
+		{"bad value", []string{"# ok", "one x"}, `line 2: "x" isn't a value for "one"`},
+		{"negative", []string{"one -1"}, `line 1: "-1" isn't a value for "one"`},
+		{"twice", []string{"one 1", "one 2"}, `line 2: "one" is in there twice`},
+		{"empty", []string{"# nothing"}, "there are no words"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseVocabulary(tt.lines)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("parseVocabulary() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+	if _, err := loadVocabulary("klingon"); err == nil {
+		t.Error("loadVocabulary() of a vocabulary that doesn't exist should fail")
+	}
+}
+
+func Test_explainer(t *testing.T) {
+	lines := []string{"twone", "abc", "4", "zéro1", "xyz"}
+	tests := []struct {
+		name  string
+		color bool
+		want  string
+	}{
+		{"plain", false, "" +
+			"    1  twone  21\n" +
+			"       ^^*$$\n" +
+			"    2  abc  no digits\n" +
+			"    3  4  44\n" +
+			"       *\n" +
+			"    4  zéro1  11\n" +
+			"           *\n" +
+			"    5  xyz  no digits\n" +
+			"2 lines had no digits: 2, 5\n"},
+		{"color", true, "" +
+			"    1  \x1b[32mtw\x1b[0m\x1b[33mo\x1b[0m\x1b[36mne\x1b[0m  21\n" +
+			"    2  abc  no digits\n" +
+			"    3  \x1b[33m4\x1b[0m  44\n" +
+			"    4  zéro\x1b[33m1\x1b[0m  11\n" +
+			"    5  xyz  no digits\n" +
+			"2 lines had no digits: 2, 5\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			// the english scanner, which doesn't know zéro
+			got := calibrate(part2Scanner, lines, &explainer{w: &buf, color: tt.color})
+			if got != 21+44+11 {
+				t.Errorf("calibrate() = %d, want %d", got, 21+44+11)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", buf.String(), tt.want)
+			}
+		})
+	}
+
+	ctx := runner.WithParams(context.Background(), runner.Params{"explain": "maybe"})
+	if _, err := part1(ctx, lines); err == nil {
+		t.Error("part1() with explain=maybe should fail")
+	}
+	ctx = runner.WithParams(context.Background(), runner.Params{"explain": "false"})
+	if e, err := explainFor(ctx, 1); e != nil || err != nil {
+		t.Errorf("explainFor() with explain=false = %v, %v", e, err)
+	}
+
+	// it writes wherever the context says, such as through the status line
+	var buf bytes.Buffer
+	ctx = progress.WithWriter(runner.WithParams(context.Background(), runner.Params{"explain": "true"}), &buf)
+	if _, err := part1(ctx, []string{"a1"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "part 1:\n    1  a1  11\n        *\n"; got != want {
+		t.Errorf("part1() explained %q, want %q", got, want)
+	}
+}
+
+// part2Regexp is how part2 used to work, with a regexp for each word, kept
+// to check the scanner against and to benchmark it.
+func part2Regexp(lines []string) int {
+	names := []string{"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine"}
+	total := 0
+	for _, line := range lines {
+		firstix := 999999
+		lastix := 0
+		firstval := 0
+		lastval := 0
+		pat := regexp.MustCompile(`[\d]`)
+		m := pat.FindAllStringIndex(line, -1)
+		if m != nil {
+			firstix = m[0][0]
+			firstval = int(line[m[0][0]] - '0')
+			lastix = m[len(m)-1][1]
+			lastval = int(line[m[len(m)-1][0]] - '0')
+		}
+		for d, w := range names {
+			pat = regexp.MustCompile(w)
+			m = pat.FindAllStringIndex(line, -1)
+			if m != nil {
+				ix1 := m[0][0]
+				if ix1 < firstix {
+					firstix = ix1
+					firstval = d
+				}
+				ix2 := m[len(m)-1][1]
+				if ix2 > lastix {
+					lastix = ix2
+					lastval = d
+				}
+			}
+		}
+		total += int(firstval*10 + lastval)
+	}
+	return total
+}
+
+func input(tb testing.TB) []string {
+	lines, err := runner.Load("..", runner.Day{Number: 1}, "input")
+	if err != nil {
+		tb.Skip(err)
+	}
+	return lines
+}
+
+func Test_part2MatchesRegexp(t *testing.T) {
+	lines := input(t)
+	got, _ := part2(context.Background(), lines)
+	if want := part2Regexp(lines); got != want {
+		t.Errorf("part2() = %d, the regexps give %d", got, want)
+	}
+	for i, line := range lines {
+		got, _ := part2(context.Background(), []string{line})
+		if want := part2Regexp([]string{line}); got != want {
+			t.Errorf("line %d %q: part2() = %d, the regexps give %d", i+1, line, got, want)
+		}
+	}
+}
+
+func Benchmark_part2(b *testing.B) {
+	lines := input(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		part2(context.Background(), lines)
+	}
+}
+
+func Benchmark_part2Regexp(b *testing.B) {
+	lines := input(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		part2Regexp(lines)
+	}
+}
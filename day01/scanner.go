@@ -0,0 +1,140 @@
+package day01
+
+import "sort"
+
+// A token is one digit found in a line, either as a digit or spelled out.
+// Start and End are byte offsets, so line[Start:End] is the text found.
+type token struct {
+	Start, End int
+	Value      int
+}
+
+// scanner finds every word of a vocabulary in a line in a single pass,
+// overlaps and all, so "eightwo" yields both eight and two. It's an
+// Aho-Corasick automaton: a trie of the words whose missing transitions
+// have been filled in from the longest suffix that is also in the trie, so
+// each byte of the line is one table lookup.
+type scanner struct {
+	states []state
+}
+
+type state struct {
+	next [256]int32
+	// the words ending here, longest first: their lengths and values
+	lengths []int
+	values  []int
+}
+
+// newScanner builds a scanner for vocab, which maps each word to its value.
+func newScanner(vocab map[string]int) *scanner {
+	s := &scanner{states: make([]state, 1)}
+	for i := range s.states[0].next {
+		s.states[0].next[i] = -1
+	}
+	// sorted so that the tokens come out in the same order every time
+	words := make([]string, 0, len(vocab))
+	for w := range vocab {
+		if w != "" {
+			words = append(words, w)
+		}
+	}
+	sort.Strings(words)
+	for _, w := range words {
+		cur := 0
+		for i := 0; i < len(w); i++ {
+			nxt := s.states[cur].next[w[i]]
+			if nxt < 0 {
+				s.states = append(s.states, state{})
+				nxt = int32(len(s.states) - 1)
+				for j := range s.states[nxt].next {
+					s.states[nxt].next[j] = -1
+				}
+				s.states[cur].next[w[i]] = nxt
+			}
+			cur = int(nxt)
+		}
+		s.states[cur].lengths = append(s.states[cur].lengths, len(w))
+		s.states[cur].values = append(s.states[cur].values, vocab[w])
+	}
+
+	// breadth first, so that a state's fallback is finished before it's used
+	fail := make([]int32, len(s.states))
+	var queue []int32
+	for b, nxt := range s.states[0].next {
+		if nxt < 0 {
+			s.states[0].next[b] = 0
+			continue
+		}
+		queue = append(queue, nxt)
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		st := &s.states[cur]
+		// a word that ends at the fallback also ends here
+		f := &s.states[fail[cur]]
+		st.lengths = append(st.lengths, f.lengths...)
+		st.values = append(st.values, f.values...)
+		for b, nxt := range st.next {
+			if nxt < 0 {
+				st.next[b] = f.next[b]
+				continue
+			}
+			fail[nxt] = f.next[b]
+			queue = append(queue, nxt)
+		}
+	}
+	return s
+}
+
+// scan calls yield with each token in line in the order they end, stopping
+// if it returns false.
+func (s *scanner) scan(line string, yield func(token) bool) {
+	cur := int32(0)
+	for i := 0; i < len(line); i++ {
+		cur = s.states[cur].next[line[i]]
+		st := &s.states[cur]
+		for j, n := range st.lengths {
+			if !yield(token{Start: i + 1 - n, End: i + 1, Value: st.values[j]}) {
+				return
+			}
+		}
+	}
+}
+
+// tokens returns every token in line, in the order they end.
+func (s *scanner) tokens(line string) []token {
+	var toks []token
+	s.scan(line, func(t token) bool {
+		toks = append(toks, t)
+		return true
+	})
+	return toks
+}
+
+// firstLast returns the token that starts first and the one that ends last
+// in line, preferring the longer of two that tie. It reports false if the
+// line has no tokens at all.
+// It's the same loop as scan, without the callback, since it's what both
+// parts spend their time in.
+func (s *scanner) firstLast(line string) (first, last token, ok bool) {
+	cur := int32(0)
+	for i := 0; i < len(line); i++ {
+		cur = s.states[cur].next[line[i]]
+		st := &s.states[cur]
+		for j, n := range st.lengths {
+			t := token{Start: i + 1 - n, End: i + 1, Value: st.values[j]}
+			if !ok {
+				first, last, ok = t, t, true
+				continue
+			}
+			if t.Start < first.Start || t.Start == first.Start && t.End > first.End {
+				first = t
+			}
+			if t.End > last.End || t.End == last.End && t.Start < last.Start {
+				last = t
+			}
+		}
+	}
+	return first, last, ok
+}
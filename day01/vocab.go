@@ -0,0 +1,90 @@
+package day01
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/kentquirk/aoc2023/parsing"
+	"github.com/kentquirk/aoc2023/runner"
+)
+
+// A vocabulary maps each word part2 looks for to the number it's worth. A
+// number of more than one digit counts as all of them: its first digit if
+// it's the first token in the line, its last if it's the last.
+type vocabulary map[string]int
+
+//go:embed vocab/*.txt
+var vocabFiles embed.FS
+
+// parseVocabulary reads a vocabulary file: one word and its value per line,
+// with blank lines and lines starting with # ignored.
+func parseVocabulary(lines []string) (vocabulary, error) {
+	v := make(vocabulary)
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields, err := parsing.Fields(line, 2)
+		if err != nil {
+			return nil, parsing.At(i+1, err)
+		}
+		n, err := parsing.Int(fields[1])
+		if err != nil || n < 0 {
+			return nil, parsing.At(i+1, fmt.Errorf("%q isn't a value for %q", fields[1], fields[0]))
+		}
+		if _, ok := v[fields[0]]; ok {
+			return nil, parsing.At(i+1, fmt.Errorf("%q is in there twice", fields[0]))
+		}
+		v[fields[0]] = n
+	}
+	if len(v) == 0 {
+		return nil, fmt.Errorf("there are no words")
+	}
+	return v, nil
+}
+
+// loadVocabulary returns the vocabulary called name, one of those in the
+// vocab directory, or read from a file if name is a path.
+func loadVocabulary(name string) (vocabulary, error) {
+	var b []byte
+	var err error
+	if runner.IsPath(name) {
+		if b, err = os.ReadFile(name); err != nil {
+			return nil, err
+		}
+	} else if b, err = vocabFiles.ReadFile(path.Join("vocab", name+".txt")); err != nil {
+		return nil, fmt.Errorf("no vocabulary %q; there are %s", name, strings.Join(vocabularies(), ", "))
+	}
+	v, err := parseVocabulary(runner.Lines(b))
+	if err != nil {
+		return nil, fmt.Errorf("vocabulary %s: %w", name, err)
+	}
+	return v, nil
+}
+
+// vocabularies returns the names of the built-in vocabularies.
+func vocabularies() []string {
+	entries, _ := vocabFiles.ReadDir("vocab")
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = strings.TrimSuffix(e.Name(), ".txt")
+	}
+	return names
+}
+
+// leading and trailing return the digit a token's value contributes when
+// it's the first or last token in a line.
+func leading(n int) int {
+	for n >= 10 {
+		n /= 10
+	}
+	return n
+}
+
+func trailing(n int) int {
+	return n % 10
+}
@@ -0,0 +1,147 @@
+package day02
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// logChoose returns the log of n choose k, or -Inf if k is out of range.
+func logChoose(n, k int) float64 {
+	if k < 0 || k > n {
+		return math.Inf(-1)
+	}
+	a, _ := math.Lgamma(float64(n + 1))
+	b, _ := math.Lgamma(float64(k + 1))
+	c, _ := math.Lgamma(float64(n - k + 1))
+	return a - b - c
+}
+
+// total returns the number of cubes in c.
+func (c colorset) total() int {
+	n := 0
+	for _, k := range c {
+		n += k
+	}
+	return n
+}
+
+// logLikelihood returns the log of the chance of pulling exactly this draw
+// out of bag in one handful of the same size. That's the multivariate
+// hypergeometric distribution: the ways of choosing each color's cubes from
+// the bag's cubes of that color, over the ways of choosing that many cubes
+// from the whole bag. It's -Inf if the draw couldn't have come from the bag.
+func (c colorset) logLikelihood(bag colorset) float64 {
+	ll := 0.0
+	for color, k := range c {
+		ll += logChoose(bag[color], k)
+	}
+	if math.IsInf(ll, -1) {
+		// the bag is short of some color, so it's short of cubes overall
+		// too, and subtracting that -Inf would give NaN
+		return ll
+	}
+	return ll - logChoose(bag.total(), c.total())
+}
+
+// logLikelihood returns the log of the chance of the game's draws, given
+// that the cubes go back in the bag after each one.
+func (g game) logLikelihood(bag colorset) float64 {
+	ll := 0.0
+	for _, d := range g {
+		ll += d.logLikelihood(bag)
+	}
+	return ll
+}
+
+// ids returns the game ids in order.
+func ids(games map[int]game) []int {
+	ids := make([]int, 0, len(games))
+	for id := range games {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// analyze writes out the likelihood of each game if the cubes came from
+// bag.
+func analyze(w io.Writer, games map[int]game, bag colorset) {
+	fmt.Fprintf(w, "bag %v\n", bag)
+	for _, id := range ids(games) {
+		ll := games[id].logLikelihood(bag)
+		if math.IsInf(ll, -1) {
+			fmt.Fprintf(w, "game %3d  impossible\n", id)
+			continue
+		}
+		fmt.Fprintf(w, "game %3d  log L %9.3f  L %.3g\n", id, ll, math.Exp(ll))
+	}
+}
+
+// ranked is a candidate bag and how well it explains the games.
+type ranked struct {
+	bag colorset
+	// ll is the log likelihood of all the games together, -Inf if any of
+	// them couldn't have come from the bag; impossible says how many.
+	ll         float64
+	impossible int
+}
+
+// rank orders the candidate bags by the likelihood of all the games, most
+// likely first. Bags that make some game impossible all tie at -Inf, so
+// those that rule out fewer games come first, and any bags still tied are
+// ordered by how they print.
+func rank(games map[int]game, candidates []colorset) []ranked {
+	rs := make([]ranked, len(candidates))
+	for i, bag := range candidates {
+		rs[i].bag = bag
+		for _, id := range ids(games) {
+			ll := games[id].logLikelihood(bag)
+			if math.IsInf(ll, -1) {
+				rs[i].impossible++
+			}
+			rs[i].ll += ll
+		}
+	}
+	sort.Slice(rs, func(i, j int) bool {
+		if rs[i].ll != rs[j].ll {
+			return rs[i].ll > rs[j].ll
+		}
+		if rs[i].impossible != rs[j].impossible {
+			return rs[i].impossible < rs[j].impossible
+		}
+		return rs[i].bag.String() < rs[j].bag.String()
+	})
+	return rs
+}
+
+// writeRanking writes out the ranked bags, best first.
+func writeRanking(w io.Writer, rs []ranked) {
+	for i, r := range rs {
+		switch r.impossible {
+		case 0:
+			fmt.Fprintf(w, "%3d  log L %11.3f", i+1, r.ll)
+		case 1:
+			fmt.Fprintf(w, "%3d  impossible for 1 game", i+1)
+		default:
+			fmt.Fprintf(w, "%3d  impossible for %d games", i+1, r.impossible)
+		}
+		fmt.Fprintf(w, "  %v\n", r.bag)
+	}
+}
+
+// parseBags parses candidate bags written like the draws of a game, with a
+// semicolon between each.
+func parseBags(s string) ([]colorset, error) {
+	var bags []colorset
+	for i, spec := range strings.Split(s, ";") {
+		bag, err := parseDraw(spec)
+		if err != nil {
+			return nil, fmt.Errorf("bag %d: %w", i+1, err)
+		}
+		bags = append(bags, bag)
+	}
+	return bags, nil
+}
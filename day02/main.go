@@ -1,79 +1,179 @@
-package main
+package day02
 
 import (
+	"context"
 	"fmt"
-	"io"
-	"log"
-	"os"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+
+	"github.com/kentquirk/aoc2023/parsing"
+	"github.com/kentquirk/aoc2023/progress"
+	"github.com/kentquirk/aoc2023/runner"
 )
 
-type colorset struct {
-	red   int
-	green int
-	blue  int
+// colorset is a multiset of cubes, counted by color. Any color name will
+// do; a color that isn't there counts as zero.
+type colorset map[string]int
+
+// colors returns the names of the colors in c, in order.
+func (c colorset) colors() []string {
+	names := make([]string, 0, len(c))
+	for color := range c {
+		names = append(names, color)
+	}
+	sort.Strings(names)
+	return names
 }
 
 func (c colorset) String() string {
-	return fmt.Sprintf("red: %d, green: %d, blue: %d", c.red, c.green, c.blue)
+	parts := make([]string, 0, len(c))
+	for _, color := range c.colors() {
+		parts = append(parts, fmt.Sprintf("%s: %d", color, c[color]))
+	}
+	return strings.Join(parts, ", ")
 }
 
 func (c colorset) isPossibleWith(bag colorset) bool {
-	return c.red <= bag.red && c.green <= bag.green && c.blue <= bag.blue
+	for color, n := range c {
+		if n > bag[color] {
+			return false
+		}
+	}
+	return true
 }
 
-func (c colorset) power() int {
-	return c.red * c.green * c.blue
+// power multiplies together the counts of the given colors, so a color
+// that never turned up makes it zero.
+func (c colorset) power(palette []string) int {
+	p := 1
+	for _, color := range palette {
+		p *= c[color]
+	}
+	return p
 }
 
-func (c *colorset) accumulate(draw colorset) {
-	if draw.red > c.red {
-		c.red = draw.red
-	}
-	if draw.green > c.green {
-		c.green = draw.green
-	}
-	if draw.blue > c.blue {
-		c.blue = draw.blue
+func (c colorset) accumulate(draw colorset) {
+	for color, n := range draw {
+		if n > c[color] {
+			c[color] = n
+		}
 	}
 }
 
 type game []colorset
 
-func parse(lines []string) map[int]game {
-	pat := regexp.MustCompile(`(\d+) (red|green|blue)`)
+var (
+	gamepat = regexp.MustCompile(`^Game (?P<id>\d+)$`)
+	cubepat = regexp.MustCompile(`^\s*(?P<count>\d+) (?P<color>[a-z]+)\s*$`)
+)
+
+// parseDraw parses a draw like "3 blue, 4 red"; errors are reported at
+// columns within the draw.
+func parseDraw(s string) (colorset, error) {
+	dr := make(colorset)
+	col := 0
+	for _, cubes := range strings.Split(s, ",") {
+		var c struct {
+			Count int
+			Color string
+		}
+		if err := parsing.Bind(cubepat, cubes, &c); err != nil {
+			return dr, parsing.Offset(col, err)
+		}
+		if _, ok := dr[c.Color]; ok {
+			return dr, parsing.Errorf(col+1, "%s twice in one draw", c.Color)
+		}
+		dr[c.Color] = c.Count
+		col += len(cubes) + 1
+	}
+	return dr, nil
+}
+
+func parse(lines []string) (map[int]game, error) {
 	games := make(map[int]game)
-	for _, line := range lines {
+	for i, line := range lines {
 		if line == "" {
 			continue
 		}
-		sp := strings.Split(line, ":")
-		gamenum, _ := strconv.Atoi(sp[0][5:])
+		head, _, err := parsing.KeyValue(line, ":")
+		if err != nil {
+			return nil, parsing.At(i+1, err)
+		}
+		var id struct{ ID int }
+		if err := parsing.Bind(gamepat, head, &id); err != nil {
+			return nil, parsing.At(i+1, err)
+		}
 		var g game
-		for _, draws := range strings.Split(sp[1], ";") {
-			ma := pat.FindAllStringSubmatch(draws, -1)
-			var dr colorset
-			for _, m := range ma {
-				switch m[2] {
-				case "red":
-					dr.red, _ = strconv.Atoi(m[1])
-				case "green":
-					dr.green, _ = strconv.Atoi(m[1])
-				case "blue":
-					dr.blue, _ = strconv.Atoi(m[1])
-				}
+		col := strings.Index(line, ":") + 1
+		for _, draw := range strings.Split(line[col:], ";") {
+			dr, err := parseDraw(draw)
+			if err != nil {
+				return nil, parsing.At(i+1, parsing.Offset(col, err))
 			}
 			g = append(g, dr)
+			col += len(draw) + 1
+		}
+		games[id.ID] = g
+	}
+	return games, nil
+}
+
+// defaultBag is the bag part1 asks about, unless the bag parameter gives
+// another.
+const defaultBag = "12 red, 13 green, 14 blue"
+
+// bagFor returns the bag given by the bag parameter, written like a draw.
+func bagFor(ctx context.Context) (colorset, error) {
+	spec, ok := runner.Param(ctx, "bag")
+	if !ok {
+		spec = defaultBag
+	}
+	bag, err := parseDraw(spec)
+	if err != nil {
+		return nil, fmt.Errorf("bag %q: %w", spec, err)
+	}
+	return bag, nil
+}
+
+// analysisPart returns the part that does the analysis: 1, unless the
+// analysis-part parameter says 2, as it must for the analysis to be seen
+// when part 2 is run on its own.
+func analysisPart(ctx context.Context) (int, error) {
+	v, ok := runner.Param(ctx, "analysis-part")
+	switch {
+	case !ok || v == "1":
+		return 1, nil
+	case v == "2":
+		return 2, nil
+	}
+	return 0, fmt.Errorf("analysis-part=%s: want 1 or 2", v)
+}
+
+// analysis writes whatever the analyze and rank parameters ask for to
+// stderr, by way of the progress line if there is one.
+func analysis(ctx context.Context, games map[int]game, bag colorset) error {
+	if v, ok := runner.Param(ctx, "analyze"); ok {
+		on, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("analyze=%s: want true or false", v)
+		}
+		if on {
+			analyze(progress.Writer(ctx), games, bag)
 		}
-		games[gamenum] = g
 	}
-	return games
+	if v, ok := runner.Param(ctx, "rank"); ok {
+		candidates, err := parseBags(v)
+		if err != nil {
+			return fmt.Errorf("rank: %w", err)
+		}
+		writeRanking(progress.Writer(ctx), rank(games, candidates))
+	}
+	return nil
 }
 
-func part1(games map[int]game) int {
-	bag := colorset{red: 12, green: 13, blue: 14}
+func part1(games map[int]game, bag colorset) int {
 	total := 0
 outer:
 	for id, g := range games {
@@ -87,36 +187,77 @@ outer:
 	return total
 }
 
+// part2 finds the power of the smallest bag each game could have come from,
+// counting every color seen in any of the games, so that a game without one
+// of them has a power of zero.
 func part2(games map[int]game) int {
+	seen := make(colorset)
+	for _, g := range games {
+		for _, d := range g {
+			seen.accumulate(d)
+		}
+	}
+	palette := seen.colors()
 	total := 0
 
 	for _, g := range games {
-		bag := colorset{}
+		bag := make(colorset)
 		for _, d := range g {
 			bag.accumulate(d)
 		}
-		total += bag.power()
+		total += bag.power(palette)
 	}
 	return total
 }
 
-func main() {
-	args := os.Args[1:]
-	name := "sample"
-	if len(args) > 0 {
-		name = args[0]
-	}
-	f, err := os.Open(fmt.Sprintf("./data/%s.txt", name))
-	if err != nil {
-		log.Fatal(err)
-	}
-	b, err := io.ReadAll(f)
-	if err != nil {
-		log.Fatal(err)
-	}
-	lines := strings.Split(string(b), "\n")
-	games := parse(lines)
-	// fmt.Println(games)
-	fmt.Println(part1(games))
-	fmt.Println(part2(games))
+func init() {
+	runner.Register(runner.Day{
+		Number: 2,
+		Part1: func(ctx context.Context, lines []string) (int, error) {
+			bag, err := bagFor(ctx)
+			if err != nil {
+				return 0, err
+			}
+			games, err := parse(lines)
+			if err != nil {
+				return 0, err
+			}
+			p, err := analysisPart(ctx)
+			if err != nil {
+				return 0, err
+			}
+			if p == 1 {
+				if err := analysis(ctx, games, bag); err != nil {
+					return 0, err
+				}
+			}
+			return part1(games, bag), nil
+		},
+		Part2: func(ctx context.Context, lines []string) (int, error) {
+			games, err := parse(lines)
+			if err != nil {
+				return 0, err
+			}
+			p, err := analysisPart(ctx)
+			if err != nil {
+				return 0, err
+			}
+			if p == 2 {
+				bag, err := bagFor(ctx)
+				if err != nil {
+					return 0, err
+				}
+				if err := analysis(ctx, games, bag); err != nil {
+					return 0, err
+				}
+			}
+			return part2(games), nil
+		},
+		Params: map[string]string{
+			"bag":           "the cubes in the bag, written like a draw (the default is " + defaultBag + ")",
+			"analyze":       "true to show how likely each game's draws are from the bag",
+			"rank":          "candidate bags separated by semicolons, to rank by how likely they make the games",
+			"analysis-part": "the part that does the analysis and ranking: 1, the default, or 2 to see them with -part 2",
+		},
+	})
 }
@@ -0,0 +1,223 @@
+package day02
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kentquirk/aoc2023/progress"
+	"github.com/kentquirk/aoc2023/runner"
+)
+
+func Test_parseDraw(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    colorset
+		wantErr string
+	}{
+		{" 3 blue, 4 red", colorset{"blue": 3, "red": 4}, ""},
+		{"1 red, 2 green, 6 blue", colorset{"red": 1, "green": 2, "blue": 6}, ""},
+		{" 2 teal, 5 magenta", colorset{"teal": 2, "magenta": 5}, ""},
+		{" 3 blue, 4 red, 1 blue", nil, "col 16: blue twice in one draw"},
+		{" 3 blue, four red", nil, `col 9: " four red" doesn't match ` + cubepat.String()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := parseDraw(tt.s)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("parseDraw() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseDraw() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_colorset(t *testing.T) {
+	bag := colorset{"red": 12, "green": 13, "blue": 14}
+	tests := []struct {
+		name     string
+		draw     colorset
+		possible bool
+	}{
+		{"empty", colorset{}, true},
+		{"fits", colorset{"red": 12, "blue": 1}, true},
+		{"too many", colorset{"green": 14}, false},
+		{"unknown color", colorset{"teal": 1}, false},
+		{"none of an unknown color", colorset{"teal": 0}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.draw.isPossibleWith(bag); got != tt.possible {
+				t.Errorf("isPossibleWith() = %v, want %v", got, tt.possible)
+			}
+		})
+	}
+
+	min := make(colorset)
+	min.accumulate(colorset{"red": 4, "blue": 3})
+	min.accumulate(colorset{"red": 1, "green": 2, "blue": 6})
+	min.accumulate(colorset{"green": 2})
+	if want := (colorset{"red": 4, "green": 2, "blue": 6}); !reflect.DeepEqual(min, want) {
+		t.Errorf("accumulate() = %v, want %v", min, want)
+	}
+	if got := min.power(bag.colors()); got != 48 {
+		t.Errorf("power() = %d, want 48", got)
+	}
+	if got := min.power([]string{"red", "teal"}); got != 0 {
+		t.Errorf("power() with a color never seen = %d, want 0", got)
+	}
+	if got := min.String(); got != "blue: 6, green: 2, red: 4" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func Test_bag(t *testing.T) {
+	lines := []string{
+		"Game 1: 3 blue, 4 red; 1 red, 2 teal",
+		"Game 2: 1 blue, 2 green; 3 green, 4 blue, 1 red",
+		"Game 3: 8 green, 6 blue, 20 red; 5 teal",
+	}
+	tests := []struct {
+		bag          string
+		part1, part2 int
+	}{
+		// game 1 can't come from a bag without teal, and the bag makes no
+		// difference to part 2, where only game 3 has all four colors
+		{"", 2, 0 + 0 + 6*8*20*5},
+		{"20 red, 13 green, 14 blue, 5 teal", 1 + 2 + 3, 0 + 0 + 6*8*20*5},
+		{"4 red, 3 green, 4 blue, 2 teal", 1 + 2, 0 + 0 + 6*8*20*5},
+		{"1 red", 0, 0 + 0 + 6*8*20*5},
+	}
+	d, _ := runner.Lookup(2)
+	for _, tt := range tests {
+		t.Run(tt.bag, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.bag != "" {
+				ctx = runner.WithParams(ctx, runner.Params{"bag": tt.bag})
+			}
+			if got, err := d.Part1(ctx, lines); err != nil || got != tt.part1 {
+				t.Errorf("part1() = %d, %v, want %d", got, err, tt.part1)
+			}
+			if got, err := d.Part2(ctx, lines); err != nil || got != tt.part2 {
+				t.Errorf("part2() = %d, %v, want %d", got, err, tt.part2)
+			}
+		})
+	}
+	ctx := runner.WithParams(context.Background(), runner.Params{"bag": "12 red, lots green"})
+	if _, err := d.Part1(ctx, lines); err == nil {
+		t.Error("part1() with a bad bag should fail")
+	}
+
+	// the analysis happens in part 1, or part 2 if analysis-part says so
+	ctx = runner.WithParams(context.Background(), runner.Params{"rank": "12 red; lots green"})
+	if _, err := d.Part1(ctx, lines); err == nil {
+		t.Error("part1() with a bad rank should fail")
+	}
+	if _, err := d.Part2(ctx, lines); err != nil {
+		t.Errorf("part2() shouldn't look at rank: %v", err)
+	}
+	ctx = runner.WithParams(context.Background(), runner.Params{"rank": "12 red; lots green", "analysis-part": "2"})
+	if _, err := d.Part1(ctx, lines); err != nil {
+		t.Errorf("part1() shouldn't look at rank with analysis-part=2: %v", err)
+	}
+	if _, err := d.Part2(ctx, lines); err == nil {
+		t.Error("part2() with a bad rank and analysis-part=2 should fail")
+	}
+	ctx = runner.WithParams(context.Background(), runner.Params{"analysis-part": "3"})
+	if _, err := d.Part2(ctx, lines); err == nil {
+		t.Error("part2() with analysis-part=3 should fail")
+	}
+	var buf bytes.Buffer
+	ctx = progress.WithWriter(runner.WithParams(context.Background(), runner.Params{"rank": "20 red, 20 green, 20 blue, 5 teal"}), &buf)
+	if _, err := d.Part1(ctx, lines); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); !strings.HasPrefix(got, "  1  log L ") {
+		t.Errorf("part1() ranked %q", got)
+	}
+}
+
+func Test_logLikelihood(t *testing.T) {
+	tests := []struct {
+		name string
+		draw colorset
+		bag  colorset
+		want float64
+	}{
+		{"one of two", colorset{"red": 1}, colorset{"red": 1, "blue": 1}, 0.5},
+		{"the whole bag", colorset{"red": 1, "blue": 1}, colorset{"red": 1, "blue": 1}, 1},
+		{"three of five", colorset{"red": 1, "blue": 2}, colorset{"red": 2, "blue": 3}, 2 * 3 / 10.0},
+		{"nothing", colorset{}, colorset{"red": 2}, 1},
+		{"none of a color", colorset{"red": 2, "teal": 0}, colorset{"red": 2, "blue": 2}, 1 / 6.0},
+		{"too many", colorset{"red": 3}, colorset{"red": 2, "blue": 3}, 0},
+		{"more than the bag", colorset{"red": 3, "blue": 3}, colorset{"red": 2, "blue": 3}, 0},
+		{"unknown color", colorset{"teal": 1}, colorset{"red": 2}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ll := tt.draw.logLikelihood(tt.bag)
+			if got := math.Exp(ll); math.IsNaN(ll) || math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("logLikelihood() = %v (L %v), want L %v", ll, got, tt.want)
+			}
+		})
+	}
+
+	g := game{{"red": 1}, {"red": 1, "blue": 2}}
+	bag := colorset{"red": 2, "blue": 3}
+	if got, want := math.Exp(g.logLikelihood(bag)), 0.4*0.6; math.Abs(got-want) > 1e-9 {
+		t.Errorf("game logLikelihood() = L %v, want %v", got, want)
+	}
+}
+
+func Test_rank(t *testing.T) {
+	games := map[int]game{
+		1: {{"red": 2}, {"red": 1, "blue": 1}},
+		2: {{"red": 1}, {"blue": 1}},
+		3: {{"red": 4}},
+	}
+	candidates, err := parseBags("1 red, 1 blue; 4 red, 1 blue; 4 red, 4 blue; 2 red, 2 blue; 4 red, 2 blue; 0 red, 9 blue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	var buf bytes.Buffer
+	rs := rank(games, candidates)
+	for _, r := range rs {
+		got = append(got, r.bag.String())
+	}
+	want := []string{
+		"blue: 1, red: 4",
+		"blue: 2, red: 4",
+		"blue: 4, red: 4",
+		// these make some game impossible, so they come in order of how
+		// many, whatever their names
+		"blue: 2, red: 2",
+		"blue: 1, red: 1",
+		"blue: 9, red: 0",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rank() = %q, want %q", got, want)
+	}
+	if rs[3].impossible != 1 || rs[4].impossible != 2 || rs[5].impossible != 3 || !math.IsInf(rs[3].ll, -1) {
+		t.Errorf("rank() impossible = %d, %d, %d, want 1, 2, 3", rs[3].impossible, rs[4].impossible, rs[5].impossible)
+	}
+	writeRanking(&buf, rs)
+	if got := buf.String(); !strings.Contains(got, "  4  impossible for 1 game  blue: 2, red: 2\n  5  impossible for 2 games  blue: 1, red: 1\n") {
+		t.Errorf("writeRanking() = %q", got)
+	}
+
+	if _, err := parseBags("2 red; 3 purple, x"); err == nil || !strings.HasPrefix(err.Error(), "bag 2: ") {
+		t.Errorf("parseBags() error = %v, want one about bag 2", err)
+	}
+}
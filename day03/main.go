@@ -1,13 +1,12 @@
-package main
+package day03
 
 import (
+	"context"
 	"fmt"
-	"io"
-	"log"
-	"os"
-	"regexp"
-	"strconv"
-	"strings"
+
+	"github.com/kentquirk/aoc2023/collections"
+	"github.com/kentquirk/aoc2023/grid"
+	"github.com/kentquirk/aoc2023/runner"
 )
 
 type number struct {
@@ -22,75 +21,74 @@ func (n *number) String() string {
 	return fmt.Sprintf("{%d (%d, %d-%d) %t}", n.value, n.row, n.firstcol, n.lastcol, n.isPartNumber)
 }
 
-type special struct {
-	row int
-	col int
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
 }
 
-func (n *number) checkAdjacent(special *special) bool {
-	if special.row >= n.row-1 && special.row <= n.row+1 {
-		if special.col >= n.firstcol-1 && special.col <= n.lastcol {
-			return true
-		}
-	}
-	return false
+func isSpecial(r rune) bool {
+	return r != 0 && r != '.' && !isDigit(r)
 }
 
-func checkAdjacent(numbers []*number, specials []*special) {
-	for _, s := range specials {
-		for _, n := range numbers {
-			if n.checkAdjacent(s) {
-				n.isPartNumber = true
-				continue
+// adjacent returns the special cells touching the number, each once.
+func (n *number) adjacent(g *grid.Grid[rune]) []grid.Point {
+	var specials []grid.Point
+	seen := make(collections.Set[grid.Point])
+	for c := n.firstcol; c < n.lastcol; c++ {
+		for _, p := range g.Neighbors8(grid.Point{Row: n.row, Col: c}) {
+			if isSpecial(g.At(p)) && !seen.Contains(p) {
+				seen.Add(p)
+				specials = append(specials, p)
 			}
 		}
 	}
+	return specials
 }
 
-func sumGears(numbers []*number, specials []*special) int {
-	sum := 0
-	for _, s := range specials {
-		count := 0
-		product := 1
-		for _, n := range numbers {
-			if n.checkAdjacent(s) {
-				count++
-				product *= n.value
-			}
+func checkAdjacent(g *grid.Grid[rune], numbers []*number) {
+	for _, n := range numbers {
+		n.isPartNumber = len(n.adjacent(g)) > 0
+	}
+}
+
+func sumGears(g *grid.Grid[rune], numbers []*number) int {
+	touching := make(map[grid.Point][]int)
+	for _, n := range numbers {
+		for _, p := range n.adjacent(g) {
+			touching[p] = append(touching[p], n.value)
 		}
-		if count == 2 {
-			sum += product
+	}
+	sum := 0
+	for _, values := range touching {
+		if len(values) == 2 {
+			sum += values[0] * values[1]
 		}
 	}
 	return sum
 }
 
-func parse(lines []string) ([]*number, []*special) {
+// parse finds the numbers in the schematic; lastcol is one past the last
+// digit.
+func parse(lines []string) (*grid.Grid[rune], []*number, error) {
+	g, err := grid.Parse(lines, func(r rune) rune { return r })
+	if err != nil {
+		return nil, nil, err
+	}
 	var numbers []*number
-	var specials []*special
-
-	numpat := regexp.MustCompile(`\d+`)
-	specialpat := regexp.MustCompile(`[^0-9.]`)
-	for i, line := range lines {
-		nums := numpat.FindAllStringIndex(line, -1)
-		for _, num := range nums {
-			v, _ := strconv.Atoi(line[num[0]:num[1]])
-			numbers = append(numbers, &number{
-				value:    v,
-				row:      i,
-				firstcol: num[0],
-				lastcol:  num[1],
-			})
-		}
-		sps := specialpat.FindAllStringIndex(line, -1)
-		for _, sp := range sps {
-			specials = append(specials, &special{
-				row: i,
-				col: sp[0],
-			})
+	for r := 0; r < g.Height(); r++ {
+		row := g.Row(r)
+		for c := 0; c < len(row); c++ {
+			if !isDigit(row[c]) {
+				continue
+			}
+			n := &number{row: r, firstcol: c}
+			for ; c < len(row) && isDigit(row[c]); c++ {
+				n.value = n.value*10 + int(row[c]-'0')
+			}
+			n.lastcol = c
+			numbers = append(numbers, n)
 		}
 	}
-	return numbers, specials
+	return g, numbers, nil
 }
 
 func total(numbers []*number) int {
@@ -103,24 +101,23 @@ func total(numbers []*number) int {
 	return total
 }
 
-func main() {
-	args := os.Args[1:]
-	name := "sample"
-	if len(args) > 0 {
-		name = args[0]
-	}
-	f, err := os.Open(fmt.Sprintf("./data/%s.txt", name))
+func part1(ctx context.Context, lines []string) (int, error) {
+	g, numbers, err := parse(lines)
 	if err != nil {
-		log.Fatal(err)
+		return 0, err
 	}
-	b, err := io.ReadAll(f)
+	checkAdjacent(g, numbers)
+	return total(numbers), nil
+}
+
+func part2(ctx context.Context, lines []string) (int, error) {
+	g, numbers, err := parse(lines)
 	if err != nil {
-		log.Fatal(err)
+		return 0, err
 	}
-	lines := strings.Split(string(b), "\n")
-	numbers, specials := parse(lines)
-	checkAdjacent(numbers, specials)
-	// fmt.Println(numbers)
-	fmt.Println(total(numbers))
-	fmt.Println(sumGears(numbers, specials))
+	return sumGears(g, numbers), nil
+}
+
+func init() {
+	runner.Register(runner.Day{Number: 3, Part1: part1, Part2: part2})
 }
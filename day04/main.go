@@ -1,45 +1,44 @@
-package main
+package day04
 
 import (
+	"context"
 	"fmt"
-	"io"
-	"log"
-	"os"
 	"regexp"
-	"strconv"
-	"strings"
-)
 
-type Set[T comparable] map[T]struct{}
+	"github.com/kentquirk/aoc2023/collections"
+	"github.com/kentquirk/aoc2023/parsing"
+	"github.com/kentquirk/aoc2023/runner"
+)
 
-func (s Set[T]) Add(i T) {
-	s[i] = struct{}{}
-}
+var (
+	splitpat = regexp.MustCompile(`:|\|`)
+	numpat   = regexp.MustCompile(`\d+`)
+	cardpat  = regexp.MustCompile(`^Card +(?P<id>\d+)$`)
+)
 
-func (s Set[T]) AddAll(i ...T) {
-	for _, v := range i {
-		s.Add(v)
+// split divides a card into its label, winning numbers and the numbers we
+// have. Blank lines give nil.
+func split(line string) ([]string, error) {
+	if line == "" {
+		return nil, nil
 	}
+	parts := splitpat.Split(line, -1)
+	if len(parts) != 3 {
+		return nil, parsing.Errorf(1, "want \"Card n: winners | numbers\", found %q", line)
+	}
+	return parts, nil
 }
 
-func (s Set[T]) Contains(i T) bool {
-	_, ok := s[i]
-	return ok
-}
-
-func (s Set[T]) Len() int {
-	return len(s)
-}
-
-func part1(lines []string) int {
-	splitpat := regexp.MustCompile(`:|\|`)
-	numpat := regexp.MustCompile(`\d+`)
+func part1(ctx context.Context, lines []string) (int, error) {
 	totalPoints := 0
-	for _, line := range lines {
-		winners := make(Set[string])
+	for i, line := range lines {
+		winners := make(collections.Set[string])
 		numWinners := 0
-		parts := splitpat.Split(line, -1)
-		if len(parts) != 3 {
+		parts, err := split(line)
+		if err != nil {
+			return 0, parsing.At(i+1, err)
+		}
+		if parts == nil {
 			continue
 		}
 		wins := numpat.FindAllString(parts[1], -1)
@@ -53,28 +52,37 @@ func part1(lines []string) int {
 			totalPoints += 1 << (numWinners - 1)
 		}
 	}
-	return totalPoints
+	return totalPoints, nil
 }
 
 type card struct {
 	id           int
+	line         int
 	numWinners   int
 	numInstances int
 }
 
-func part2(lines []string) int {
-	splitpat := regexp.MustCompile(`:|\|`)
-	numpat := regexp.MustCompile(`\d+`)
+func part2(ctx context.Context, lines []string) (int, error) {
 	cards := make(map[int]*card)
 	lastid := 0
-	for _, line := range lines {
-		winners := make(Set[string])
+	for i, line := range lines {
+		winners := make(collections.Set[string])
 		numWinners := 0
-		parts := splitpat.Split(line, -1)
-		if len(parts) != 3 {
+		parts, err := split(line)
+		if err != nil {
+			return 0, parsing.At(i+1, err)
+		}
+		if parts == nil {
 			continue
 		}
-		id, _ := strconv.Atoi(numpat.FindString(parts[0]))
+		var c struct{ ID int }
+		if err := parsing.Bind(cardpat, parts[0], &c); err != nil {
+			return 0, parsing.At(i+1, err)
+		}
+		id := c.ID
+		if id != lastid+1 {
+			return 0, parsing.At(i+1, fmt.Errorf("card %d follows card %d", id, lastid))
+		}
 		wins := numpat.FindAllString(parts[1], -1)
 		winners.AddAll(wins...)
 		for _, card := range numpat.FindAllString(parts[2], -1) {
@@ -84,6 +92,7 @@ func part2(lines []string) int {
 		}
 		cards[id] = &card{
 			id:           id,
+			line:         i + 1,
 			numWinners:   numWinners,
 			numInstances: 1,
 		}
@@ -92,32 +101,18 @@ func part2(lines []string) int {
 	totalPoints := 0
 	for id := 1; id <= lastid; id++ {
 		c := cards[id]
-		if c.numWinners > 0 {
-			for i := 1; i <= c.numWinners; i++ {
-				cards[id+i].numInstances += c.numInstances
-			}
+		if id+c.numWinners > lastid {
+			return 0, parsing.At(c.line, fmt.Errorf("card %d wins copies of cards up to %d, but the last is %d", id, id+c.numWinners, lastid))
+		}
+		for i := 1; i <= c.numWinners; i++ {
+			cards[id+i].numInstances += c.numInstances
 		}
 		totalPoints += c.numInstances
 	}
 
-	return totalPoints
+	return totalPoints, nil
 }
 
-func main() {
-	args := os.Args[1:]
-	name := "sample"
-	if len(args) > 0 {
-		name = args[0]
-	}
-	f, err := os.Open(fmt.Sprintf("./data/%s.txt", name))
-	if err != nil {
-		log.Fatal(err)
-	}
-	b, err := io.ReadAll(f)
-	if err != nil {
-		log.Fatal(err)
-	}
-	lines := strings.Split(string(b), "\n")
-	fmt.Println(part1(lines))
-	fmt.Println(part2(lines))
+func init() {
+	runner.Register(runner.Day{Number: 4, Part1: part1, Part2: part2})
 }
@@ -0,0 +1,39 @@
+package day04
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kentquirk/aoc2023/parsing"
+)
+
+func Test_part2Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		lines     []string
+		line, col int
+		want      string
+	}{
+		{"no colon", []string{"Card 1: 41 48 | 83 86", "Card 2 41 48 83"}, 2, 1, `want "Card n: winners | numbers"`},
+		{"not a card", []string{"Card 1: 41 48 | 83 86", "Crd 2: 41 | 48"}, 2, 1, `"Crd 2" doesn't match`},
+		{"out of order", []string{"Card 2: 41 48 | 83 86", "Card 1: 41 | 48"}, 1, 0, "card 2 follows card 0"},
+		{"wins past the end", []string{"Card 1: 41 48 | 83 86", "Card 2: 1 2 | 1 2"}, 2, 0, "card 2 wins copies of cards up to 4, but the last is 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := part2(context.Background(), tt.lines)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("part2() error = %v, want one saying %q", err, tt.want)
+			}
+			var pe *parsing.Error
+			if !errors.As(err, &pe) {
+				t.Fatalf("part2() = %v, want a *parsing.Error", err)
+			}
+			if pe.Line != tt.line || pe.Col != tt.col {
+				t.Errorf("part2() error at %d:%d, want %d:%d (%v)", pe.Line, pe.Col, tt.line, tt.col, err)
+			}
+		})
+	}
+}
@@ -1,20 +1,22 @@
-package main
+package day05
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"io"
-	"log"
 	"math"
-	"os"
 	"regexp"
-	"strconv"
-	"strings"
+
+	"github.com/kentquirk/aoc2023/interval"
+	"github.com/kentquirk/aoc2023/parsing"
+	"github.com/kentquirk/aoc2023/runner"
+	"github.com/kentquirk/aoc2023/trace"
 )
 
+// FarmMapRange moves the values in source up by offset.
 type FarmMapRange struct {
-	destStart   int
-	sourceStart int
-	count       int
+	source interval.Interval
+	offset int
 }
 
 type FarmMap struct {
@@ -27,60 +29,113 @@ type FarmMap struct {
 // and return the new value
 func (m *FarmMap) Lookup(value int) (string, int) {
 	for _, r := range m.ranges {
-		if r.sourceStart <= value && value < r.sourceStart+r.count {
-			offset := value - r.sourceStart
-			return m.to, r.destStart + offset
+		if r.source.Contains(value) {
+			return m.to, value + r.offset
 		}
 	}
 	return m.to, value
 }
 
+// LookupSet maps a whole set of values at once.
+func (m *FarmMap) LookupSet(values interval.Set) (string, interval.Set) {
+	var out interval.Set
+	rest := values
+	for _, r := range m.ranges {
+		src := interval.NewSet(r.source)
+		out = out.Union(rest.Intersect(src).Shift(r.offset))
+		rest = rest.Subtract(src)
+	}
+	return m.to, out.Union(rest)
+}
+
 type Table map[string]FarmMap
 
+var tconvert = trace.New("day05", "convert")
+
 func (t Table) Convert(have string, want string, value int) int {
 	for have != want {
-		// fmt.Printf("%s(%d) -> ", have, value)
+		tconvert.Printf("%s(%d) ->", have, value)
 		if m, ok := t[have]; ok {
 			have, value = m.Lookup(value)
 		}
 	}
-	// fmt.Printf("END: %s(%d)\n", have, value)
+	tconvert.Printf("END: %s(%d)", have, value)
 	return value
 }
 
-func parse(data string) (Table, []int) {
+// ConvertSet is Convert for a set of values.
+func (t Table) ConvertSet(have string, want string, values interval.Set) interval.Set {
+	for have != want {
+		tconvert.Log("converting", "from", have, "values", values)
+		if m, ok := t[have]; ok {
+			have, values = m.LookupSet(values)
+		}
+	}
+	return values
+}
+
+var (
+	seedpat = regexp.MustCompile(`^seeds:(?P<seeds>[\d ]*)$`)
+	mappat  = regexp.MustCompile(`^(?P<from>\w+)-to-(?P<to>\w+) map:$`)
+)
+
+func parse(lines []string) (Table, []int, error) {
 	table := make(Table)
-	var seeds []int
-	blocks := strings.Split(data, "\n\n")
-	numpat := regexp.MustCompile(`\d+`)
-	for _, s := range numpat.FindAllString(blocks[0], -1) {
-		n, _ := strconv.Atoi(s)
-		seeds = append(seeds, n)
+	blocks := parsing.Blocks(lines)
+	if len(blocks) == 0 {
+		return nil, nil, errors.New("no seeds")
+	}
+	var s struct{ Seeds string }
+	if err := parsing.Bind(seedpat, blocks[0].Lines[0], &s); err != nil {
+		return nil, nil, blocks[0].At(0, err)
 	}
-	blocks = blocks[1:]
-
-	for _, block := range blocks {
-		lines := strings.Split(block, "\n")
-		namepat := regexp.MustCompile(`\w+`)
-		names := namepat.FindAllString(lines[0], -1)
-		from, to := names[0], names[2]
-		lines = lines[1:]
-		m := FarmMap{from, to, []FarmMapRange{}}
-		for _, line := range lines {
-			var destStart, srcStart, count int
-			fmt.Sscanf(line, "%d %d %d", &destStart, &srcStart, &count)
+	seeds, err := parsing.IntFields(s.Seeds, -1)
+	if err != nil {
+		return nil, nil, blocks[0].At(0, parsing.Offset(len("seeds:"), err))
+	}
+
+	for _, block := range blocks[1:] {
+		var names struct{ From, To string }
+		if err := parsing.Bind(mappat, block.Lines[0], &names); err != nil {
+			return nil, nil, block.At(0, err)
+		}
+		m := FarmMap{names.From, names.To, []FarmMapRange{}}
+		for i, line := range block.Lines[1:] {
+			ns, err := parsing.IntFields(line, 3)
+			if err != nil {
+				return nil, nil, block.At(i+1, err)
+			}
+			destStart, srcStart, count := ns[0], ns[1], ns[2]
 			m.ranges = append(m.ranges, FarmMapRange{
-				destStart:   destStart,
-				sourceStart: srcStart,
-				count:       count,
+				source: interval.Span(srcStart, count),
+				offset: destStart - srcStart,
 			})
 		}
-		table[from] = m
+		table[names.From] = m
+	}
+	// Convert loops until it gets to a location, so make sure it can.
+	have := "seed"
+	for i := 0; i <= len(table); i++ {
+		if have == "location" {
+			break
+		}
+		m, ok := table[have]
+		if !ok {
+			return nil, nil, fmt.Errorf("no map from %s", have)
+		}
+		have = m.to
+	}
+	if have != "location" {
+		return nil, nil, errors.New("the maps never get from seed to location")
 	}
-	return table, seeds
+	return table, seeds, nil
 }
 
-func part1(t Table, seeds []int) int {
+func part1(ctx context.Context, lines []string) (int, error) {
+	t, seeds, err := parse(lines)
+	if err != nil {
+		return 0, err
+	}
 	lowest := math.MaxInt64
 	for _, seed := range seeds {
 		v := t.Convert("seed", "location", seed)
@@ -88,42 +143,28 @@ func part1(t Table, seeds []int) int {
 			lowest = v
 		}
 	}
-	return lowest
-}
-
-// This is super slow. It could be solved by
-// dividing the ranges into linear sub-parts and finding
-// the minimum of those. But I'm also kind of done with
-// this problem.
-func part2(t Table, seeds []int) int {
-	lowest := math.MaxInt64
-	for i := 0; i < len(seeds); i += 2 {
-		seed := seeds[i]
-		for j := 0; j < seeds[i+1]; j++ {
-			v := t.Convert("seed", "location", seed+j)
-			if v < lowest {
-				lowest = v
-			}
-		}
-	}
-	return lowest
+	return lowest, nil
 }
 
-func main() {
-	args := os.Args[1:]
-	name := "sample"
-	if len(args) > 0 {
-		name = args[0]
-	}
-	f, err := os.Open(fmt.Sprintf("./data/%s.txt", name))
+// part2's seeds are ranges, too many to convert one at a time, so we push
+// the whole ranges through the maps, splitting them wherever a map does.
+func part2(ctx context.Context, lines []string) (int, error) {
+	t, seeds, err := parse(lines)
 	if err != nil {
-		log.Fatal(err)
+		return 0, err
 	}
-	b, err := io.ReadAll(f)
-	if err != nil {
-		log.Fatal(err)
+	if len(seeds)%2 != 0 {
+		return 0, fmt.Errorf("%d seed numbers don't make ranges", len(seeds))
+	}
+	var ranges []interval.Interval
+	for i := 0; i+1 < len(seeds); i += 2 {
+		ranges = append(ranges, interval.Span(seeds[i], seeds[i+1]))
 	}
-	t, seeds := parse(string(b))
-	fmt.Println(part1(t, seeds))
-	fmt.Println(part2(t, seeds))
+	locations := t.ConvertSet("seed", "location", interval.NewSet(ranges...))
+	lowest, _ := locations.Min()
+	return lowest, nil
+}
+
+func init() {
+	runner.Register(runner.Day{Number: 5, Part1: part1, Part2: part2})
 }
@@ -0,0 +1,40 @@
+package day05
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kentquirk/aoc2023/parsing"
+)
+
+func Test_parseErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		lines     []string
+		line, col int
+		want      string
+	}{
+		{"empty", nil, 0, 0, "no seeds"},
+		{"bad seeds", []string{"seeds: 79 x4", "", "seed-to-location map:", "1 2 3"}, 1, 1, `"seeds: 79 x4" doesn't match`},
+		{"bad map name", []string{"seeds: 79", "", "seed-to-location map:", "1 2 3", "", "soil map:", "1 2 3"}, 6, 1, `"soil map:" doesn't match`},
+		{"short range", []string{"seeds: 79", "", "seed-to-location map:", "1 2 3", "1 2"}, 5, 1, "want 3 numbers, found 2"},
+		{"dead end", []string{"seeds: 79", "", "seed-to-soil map:", "1 2 3"}, 0, 0, "no map from soil"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := parse(tt.lines)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("parse() error = %v, want one saying %q", err, tt.want)
+			}
+			line, col := 0, 0
+			var pe *parsing.Error
+			if errors.As(err, &pe) {
+				line, col = pe.Line, pe.Col
+			}
+			if line != tt.line || col != tt.col {
+				t.Errorf("parse() error at %d:%d, want %d:%d (%v)", line, col, tt.line, tt.col, err)
+			}
+		})
+	}
+}
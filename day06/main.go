@@ -1,13 +1,14 @@
-package main
+package day06
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"io"
-	"log"
-	"os"
-	"regexp"
-	"strconv"
+	"log/slog"
 	"strings"
+
+	"github.com/kentquirk/aoc2023/parsing"
+	"github.com/kentquirk/aoc2023/runner"
 )
 
 type td struct {
@@ -31,28 +32,70 @@ func (t td) BeatsRecord(b int) bool {
 	return t.DistanceForPress(b) > t.recordDist
 }
 
-func parse1(lines []string) []td {
-	numpat := regexp.MustCompile(`\d+`)
-	ts := numpat.FindAllString(lines[0], -1)
-	ds := numpat.FindAllString(lines[1], -1)
+// fields returns the numbers from the "Time:" and "Distance:" lines.
+func fields(lines []string) (ts, ds []string, err error) {
+	if len(lines) < 2 {
+		return nil, nil, errors.New("want a Time line and a Distance line")
+	}
+	for i, want := range []string{"Time", "Distance"} {
+		key, value, err := parsing.KeyValue(lines[i], ":")
+		if err == nil && key != want {
+			err = parsing.Errorf(1, "want %s, found %q", want, key)
+		}
+		if err != nil {
+			return nil, nil, parsing.At(i+1, err)
+		}
+		col := strings.Index(lines[i], ":") + 1
+		if _, err := parsing.IntFields(lines[i][col:], -1); err != nil {
+			return nil, nil, parsing.At(i+1, parsing.Offset(col, err))
+		}
+		if i == 0 {
+			ts = strings.Fields(value)
+		} else {
+			ds = strings.Fields(value)
+		}
+	}
+	if len(ts) != len(ds) {
+		return nil, nil, fmt.Errorf("%d times but %d distances", len(ts), len(ds))
+	}
+	return ts, ds, nil
+}
+
+func parse1(lines []string) ([]td, error) {
+	ts, ds, err := fields(lines)
+	if err != nil {
+		return nil, err
+	}
 	times := make([]td, len(ts))
 	for i := range ts {
-		times[i].raceTime, _ = strconv.Atoi(ts[i])
-		times[i].recordDist, _ = strconv.Atoi(ds[i])
+		// fields has checked these
+		times[i].raceTime, _ = parsing.Int(ts[i])
+		times[i].recordDist, _ = parsing.Int(ds[i])
 	}
-	return times
+	return times, nil
 }
 
-func parse2(lines []string) td {
-	numpat := regexp.MustCompile(`\d+`)
-	ts := numpat.FindAllString(lines[0], -1)
-	ds := numpat.FindAllString(lines[1], -1)
-	times, _ := strconv.Atoi(strings.Join(ts, ""))
-	dists, _ := strconv.Atoi(strings.Join(ds, ""))
-	return td{times, dists}
+func parse2(lines []string) (td, error) {
+	ts, ds, err := fields(lines)
+	if err != nil {
+		return td{}, err
+	}
+	times, err := parsing.Int(strings.Join(ts, ""))
+	if err != nil {
+		return td{}, parsing.At(1, err)
+	}
+	dists, err := parsing.Int(strings.Join(ds, ""))
+	if err != nil {
+		return td{}, parsing.At(2, err)
+	}
+	return td{times, dists}, nil
 }
 
-func part1(races []td) int {
+func part1(ctx context.Context, lines []string) (int, error) {
+	races, err := parse1(lines)
+	if err != nil {
+		return 0, err
+	}
 	product := 1
 	for _, race := range races {
 		count := 0
@@ -63,7 +106,7 @@ func part1(races []td) int {
 		}
 		product *= count
 	}
-	return product
+	return product, nil
 }
 
 func newtonsMethod(race td, startingGuess int) int {
@@ -78,7 +121,7 @@ func newtonsMethod(race td, startingGuess int) int {
 	for {
 		attempts++
 		if attempts > 1000 {
-			fmt.Println("too many attempts")
+			slog.Warn("too many attempts", "race", race.raceTime, "guess", guess)
 			break
 		}
 		if guess == lastguess {
@@ -89,7 +132,7 @@ func newtonsMethod(race td, startingGuess int) int {
 				if race.BeatsRecord(guess - 1) {
 					return guess - 1
 				}
-				fmt.Println("failure!")
+				slog.Warn("no press near the guess beats the record", "race", race.raceTime, "guess", guess)
 			}
 			return guess
 		}
@@ -108,29 +151,18 @@ func newtonsMethod(race td, startingGuess int) int {
 	return guess
 }
 
-func part2(race td) int {
-	fmt.Println(race)
+func part2(ctx context.Context, lines []string) (int, error) {
+	race, err := parse2(lines)
+	if err != nil {
+		return 0, err
+	}
+	slog.Debug("race", "time", race.raceTime, "record", race.recordDist)
 	min := newtonsMethod(race, 10)
 	max := newtonsMethod(race, race.raceTime)
-	fmt.Println(min, max)
-	return max - min + 1
+	slog.Debug("presses that beat the record", "min", min, "max", max)
+	return max - min + 1, nil
 }
 
-func main() {
-	args := os.Args[1:]
-	name := "sample"
-	if len(args) > 0 {
-		name = args[0]
-	}
-	f, err := os.Open(fmt.Sprintf("./data/%s.txt", name))
-	if err != nil {
-		log.Fatal(err)
-	}
-	b, err := io.ReadAll(f)
-	if err != nil {
-		log.Fatal(err)
-	}
-	lines := strings.Split(string(b), "\n")
-	fmt.Println(part1(parse1(lines)))
-	fmt.Println(part2(parse2(lines)))
+func init() {
+	runner.Register(runner.Day{Number: 6, Part1: part1, Part2: part2})
 }
@@ -1,13 +1,15 @@
-package main
+package day07
 
 import (
+	"context"
 	"fmt"
-	"io"
-	"log"
-	"os"
+	"log/slog"
 	"slices"
-	"strconv"
 	"strings"
+
+	"github.com/kentquirk/aoc2023/collections"
+	"github.com/kentquirk/aoc2023/parsing"
+	"github.com/kentquirk/aoc2023/runner"
 )
 
 type handType int
@@ -55,8 +57,14 @@ type hand struct {
 const ordering = "23456789TJQKA"
 const jokerOrdering = "J23456789TQKA"
 
-func NewHand(cards string, bid string, withJokers bool) *hand {
-	b, _ := strconv.Atoi(bid)
+func NewHand(cards string, bid string, withJokers bool) (*hand, error) {
+	if len(cards) != 5 || strings.Trim(cards, ordering) != "" {
+		return nil, parsing.Errorf(1, "%q is not a hand of 5 cards", cards)
+	}
+	b, err := parsing.Int(bid)
+	if err != nil {
+		return nil, parsing.Offset(len(cards)+1, err)
+	}
 	ordering := ordering
 	if withJokers {
 		ordering = jokerOrdering
@@ -69,7 +77,7 @@ func NewHand(cards string, bid string, withJokers bool) *hand {
 		}, cards),
 	}
 	h.SetType(withJokers)
-	return h
+	return h, nil
 }
 
 func (h *hand) String() string {
@@ -77,9 +85,9 @@ func (h *hand) String() string {
 }
 
 func (h *hand) SetType(withJokers bool) {
-	m := make(map[rune]int)
+	m := make(collections.Counter[rune])
 	for _, c := range h.cards {
-		m[c]++
+		m.Add(c)
 	}
 	paircount := 0
 	jokercount := 0
@@ -170,47 +178,41 @@ func Compare(lhs, rhs *hand) int {
 	return strings.Compare(rhs.comparableCards, lhs.comparableCards)
 }
 
-func eval(lines []string, withJokers bool) int {
+func eval(lines []string, withJokers bool) (int, error) {
 	hands := make([]*hand, 0)
-	for _, line := range lines {
-		parts := strings.Split(line, " ")
-		h := NewHand(parts[0], parts[1], withJokers)
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		parts, err := parsing.Fields(line, 2)
+		if err != nil {
+			return 0, parsing.At(i+1, err)
+		}
+		h, err := NewHand(parts[0], parts[1], withJokers)
+		if err != nil {
+			return 0, parsing.At(i+1, err)
+		}
 		hands = append(hands, h)
 	}
 	slices.SortFunc(hands, Compare)
 	for _, h := range hands {
-		fmt.Println(h)
+		slog.Debug("ranked", "hand", h)
 	}
 	winnings := 0
 	for i, h := range hands {
 		winnings += h.bid * (len(hands) - i)
 	}
-	return winnings
+	return winnings, nil
 }
 
-func part1(lines []string) int {
+func part1(ctx context.Context, lines []string) (int, error) {
 	return eval(lines, false)
 }
 
-func part2(lines []string) int {
+func part2(ctx context.Context, lines []string) (int, error) {
 	return eval(lines, true)
 }
 
-func main() {
-	args := os.Args[1:]
-	name := "sample"
-	if len(args) > 0 {
-		name = args[0]
-	}
-	f, err := os.Open(fmt.Sprintf("./data/%s.txt", name))
-	if err != nil {
-		log.Fatal(err)
-	}
-	b, err := io.ReadAll(f)
-	if err != nil {
-		log.Fatal(err)
-	}
-	lines := strings.Split(string(b), "\n")
-	fmt.Println(part1(lines))
-	fmt.Println(part2(lines))
+func init() {
+	runner.Register(runner.Day{Number: 7, Part1: part1, Part2: part2})
 }
@@ -1,4 +1,4 @@
-package main
+package day07
 
 import "testing"
 
@@ -26,7 +26,10 @@ func Test_hand_SetType(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.cards, func(t *testing.T) {
-			h := NewHand(tt.cards, "0", tt.withJokers)
+			h, err := NewHand(tt.cards, "0", tt.withJokers)
+			if err != nil {
+				t.Fatal(err)
+			}
 			if h.typ.String() != tt.want {
 				t.Errorf("hand.SetType() = %v, want %v", h.typ, tt.want)
 			}
@@ -1,159 +1,176 @@
-package main
+package day08
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"io"
-	"log"
-	"os"
+	"log/slog"
 	"regexp"
+	"slices"
 	"strings"
+
+	"github.com/kentquirk/aoc2023/numtheory"
+	"github.com/kentquirk/aoc2023/parsing"
+	"github.com/kentquirk/aoc2023/runner"
+	"github.com/kentquirk/aoc2023/trace"
 )
 
+var tend = trace.New("day08", "end")
+
 type node struct {
 	name  string
 	left  *node
 	right *node
 }
 
-// do this in two passes so we can build the tree with pointers
-// instead of relying on names for indexing
-func build1(lines []string) *node {
-	namepat := regexp.MustCompile(`\w+`)
-	names := make(map[string]*node)
-	for _, line := range lines {
-		words := namepat.FindAllString(line, -1)
-		name := words[0]
-		node := &node{name: name}
-		names[name] = node
+// move returns the node that step number steps of the sequence leads to.
+func (n *node) move(sequence string, steps int) (*node, error) {
+	i := steps % len(sequence)
+	switch sequence[i] {
+	case 'L':
+		return n.left, nil
+	case 'R':
+		return n.right, nil
 	}
-	for _, line := range lines {
-		words := namepat.FindAllString(line, -1)
-		name := words[0]
-		left := words[1]
-		right := words[2]
-		n := names[name]
-		n.left = names[left]
-		n.right = names[right]
-	}
-	return names["AAA"]
+	return nil, parsing.At(1, parsing.Errorf(i+1, "%q is not L or R", sequence[i]))
 }
 
-func build2(lines []string) []*node {
-	namepat := regexp.MustCompile(`\w+`)
+var (
+	seqpat  = regexp.MustCompile(`^[LR]+$`)
+	nodepat = regexp.MustCompile(`^(?P<name>\w+) = \((?P<left>\w+), (?P<right>\w+)\)$`)
+)
+
+// parse returns the sequence of moves and the nodes in the order they were
+// listed.
+// It makes two passes so we can build the tree with pointers
+// instead of relying on names for indexing.
+func parse(lines []string) (string, []*node, error) {
+	if len(lines) < 3 || lines[1] != "" {
+		return "", nil, errors.New("want a sequence, a blank line and then the nodes")
+	}
+	sequence := lines[0]
+	if !seqpat.MatchString(sequence) {
+		return "", nil, parsing.At(1, parsing.Errorf(1, "%q is not a sequence of L and R", sequence))
+	}
+	type entry struct{ Name, Left, Right string }
+	var entries []entry
 	names := make(map[string]*node)
-	var roots []*node
-	for _, line := range lines {
-		words := namepat.FindAllString(line, -1)
-		name := words[0]
-		node := &node{name: name}
-		if name[2] == 'A' {
-			roots = append(roots, node)
+	var nodes []*node
+	for i, line := range lines[2:] {
+		if line == "" {
+			continue
 		}
-		names[name] = node
+		var e entry
+		if err := parsing.Bind(nodepat, line, &e); err != nil {
+			return "", nil, parsing.At(i+3, err)
+		}
+		if _, ok := names[e.Name]; ok {
+			return "", nil, parsing.At(i+3, fmt.Errorf("node %s is listed twice", e.Name))
+		}
+		n := &node{name: e.Name}
+		names[e.Name] = n
+		nodes = append(nodes, n)
+		entries = append(entries, e)
 	}
-	for _, line := range lines {
-		words := namepat.FindAllString(line, -1)
-		name := words[0]
-		left := words[1]
-		right := words[2]
-		n := names[name]
-		n.left = names[left]
-		n.right = names[right]
+	for i, e := range entries {
+		n := nodes[i]
+		n.left, n.right = names[e.Left], names[e.Right]
+		if n.left == nil || n.right == nil {
+			return "", nil, fmt.Errorf("node %s leads to a node that isn't listed", e.Name)
+		}
 	}
-	return roots
+	return sequence, nodes, nil
 }
 
-func part1(lines []string) int {
-	sequence := lines[0]
-	node := build1(lines[2:])
+func part1(ctx context.Context, lines []string) (int, error) {
+	sequence, nodes, err := parse(lines)
+	if err != nil {
+		return 0, err
+	}
+	i := slices.IndexFunc(nodes, func(n *node) bool { return n.name == "AAA" })
+	if i < 0 {
+		return 0, errors.New("there is no node AAA")
+	}
+	node := nodes[i]
 	steps := 0
 	for ; node.name != "ZZZ"; steps++ {
-		dir := sequence[steps%len(sequence)]
-		switch dir {
-		case 'L':
-			node = node.left
-		case 'R':
-			node = node.right
-		default:
-			panic("OOPS")
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		var err error
+		if node, err = node.move(sequence, steps); err != nil {
+			return 0, err
 		}
 	}
-	return steps
-}
-
-func leastCommonMultiple(a []int) int {
-	lcm := a[0]
-	for _, n := range a[1:] {
-		lcm = lcm * n / gcd(lcm, n)
-	}
-	return lcm
-}
-
-func gcd(a, b int) int {
-	for b != 0 {
-		a, b = b, a%b
-	}
-	return a
+	return steps, nil
 }
 
 // These sequences are periodic, so we can just find the period
-// and then find the least common multiple of the periods.
+// and then line them up with the Chinese Remainder Theorem (which comes to
+// the least common multiple of the periods when, as in the real input, each
+// first reaches a Z after exactly one period).
 // We do that by running them all long enough so that they get past the first
 // period, then we find the difference between the most recent pair for
 // each sequence.
-func part2(lines []string) int {
-	sequence := lines[0]
-	roots := build2(lines[2:])
+func part2(ctx context.Context, lines []string) (int, error) {
+	sequence, nodes, err := parse(lines)
+	if err != nil {
+		return 0, err
+	}
+	var roots []*node
+	for _, n := range nodes {
+		if strings.HasSuffix(n.name, "A") {
+			roots = append(roots, n)
+		}
+	}
+	if len(roots) == 0 {
+		return 0, errors.New("there are no nodes ending in A")
+	}
 	pairs := make([]struct {
 		a, b int
 	}, len(roots))
 	for steps := 0; steps < 100000; steps++ {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
 		endcount := 0
 		for i, n := range roots {
-			dir := sequence[steps%len(sequence)]
-			switch dir {
-			case 'L':
-				n = n.left
-			case 'R':
-				n = n.right
-			default:
-				panic("OOPS")
+			n, err := n.move(sequence, steps)
+			if err != nil {
+				return 0, err
 			}
-			if n.name[2] == 'Z' {
+			if strings.HasSuffix(n.name, "Z") {
 				endcount++
 				pairs[i].a = pairs[i].b
 				pairs[i].b = steps
-				// fmt.Printf("root %d: %d\n", i, steps)
+				tend.Log("end", "root", i, "steps", steps)
 			}
 			roots[i] = n
 		}
 		if endcount == len(roots) {
-			return steps
+			// steps counts from 0, so the move we just made is number steps+1
+			return steps + 1, nil
 		}
 	}
+	// each root is at a Z on move b+1 and every period moves after that,
+	// so we want the first move that is all of those at once
 	diffs := make([]int, len(pairs))
+	ends := make([]int, len(pairs))
 	for i, p := range pairs {
 		diffs[i] = p.b - p.a
+		ends[i] = p.b + 1
 	}
-	fmt.Println(diffs)
-	return leastCommonMultiple(diffs)
-}
-
-func main() {
-	args := os.Args[1:]
-	name := "sample"
-	if len(args) > 0 {
-		name = args[0]
-	}
-	f, err := os.Open(fmt.Sprintf("./data/%s.txt", name))
+	slog.Debug("periods", "diffs", diffs)
+	n, m, err := numtheory.CRT(ends, diffs)
 	if err != nil {
-		log.Fatal(err)
+		return 0, fmt.Errorf("lining up periods %v: %w", diffs, err)
 	}
-	b, err := io.ReadAll(f)
-	if err != nil {
-		log.Fatal(err)
+	if n == 0 {
+		return m, nil
 	}
-	lines := strings.Split(string(b), "\n")
-	fmt.Println(part1(lines))
-	fmt.Println(part2(lines))
+	return n, nil
+}
+
+func init() {
+	runner.Register(runner.Day{Number: 8, Part1: part1, Part2: part2})
 }
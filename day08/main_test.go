@@ -0,0 +1,44 @@
+package day08
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kentquirk/aoc2023/parsing"
+)
+
+func Test_parseErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		lines     []string
+		line, col int
+		want      string
+	}{
+		{"no blank line", []string{"RL", "AAA = (AAA, AAA)"}, 0, 0, "want a sequence, a blank line and then the nodes"},
+		{"bad sequence", []string{"RX", "", "AAA = (AAA, AAA)"}, 1, 1, `"RX" is not a sequence of L and R`},
+		{"bad node", []string{"RL", "", "AAA = (AAA, AAA)", "BBB = BBB"}, 4, 1, `"BBB = BBB" doesn't match`},
+		{"twice", []string{"RL", "", "AAA = (AAA, AAA)", "AAA = (AAA, AAA)"}, 4, 0, "node AAA is listed twice"},
+		{"dangling", []string{"RL", "", "AAA = (BBB, AAA)"}, 0, 0, "node AAA leads to a node that isn't listed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := parse(tt.lines)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("parse() error = %v, want one saying %q", err, tt.want)
+			}
+			line, col := 0, 0
+			var pe *parsing.Error
+			if errors.As(err, &pe) {
+				line, col = pe.Line, pe.Col
+			}
+			if line != tt.line || col != tt.col {
+				t.Errorf("parse() error at %d:%d, want %d:%d (%v)", line, col, tt.line, tt.col, err)
+			}
+		})
+	}
+	if _, err := part2(context.Background(), []string{"RL", "", "BBZ = (BBZ, BBZ)"}); err == nil {
+		t.Error("part2() with no nodes ending in A should fail")
+	}
+}
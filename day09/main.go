@@ -1,11 +1,13 @@
-package main
+package day09
 
 import (
+	"context"
 	"fmt"
-	"io"
-	"log"
-	"os"
 	"strings"
+
+	"github.com/kentquirk/aoc2023/parsing"
+	"github.com/kentquirk/aoc2023/runner"
+	"github.com/kentquirk/aoc2023/trace"
 )
 
 type sequence []int
@@ -24,14 +26,16 @@ func (s sequence) Deltas() (sequence, bool) {
 	return deltas, allzeros
 }
 
-func BuildSequence(line string) sequence {
-	var s sequence
-	for _, v := range strings.Split(line, " ") {
-		var i int
-		fmt.Sscanf(v, "%d", &i)
-		s = append(s, i)
+func BuildSequence(line string) (sequence, error) {
+	s, err := parsing.IntFields(line, -1)
+	if err != nil {
+		return nil, err
 	}
-	return s
+	if len(s) == 0 {
+		// NextValue and PrevValue need something to extrapolate from
+		return nil, parsing.Errorf(1, "no numbers")
+	}
+	return sequence(s), nil
 }
 
 func BuildTriangle(s sequence) triangle {
@@ -72,47 +76,52 @@ func (t triangle) String() string {
 	return s.String()
 }
 
-func part1(lines []string) int {
+// ttriangle traces each line's value; at verbosity 2 it shows the whole
+// triangle as well.
+var ttriangle = trace.New("day09", "triangle")
+
+func part1(ctx context.Context, lines []string) (int, error) {
 	total := 0
-	for _, line := range lines {
-		s := BuildSequence(line)
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		s, err := BuildSequence(line)
+		if err != nil {
+			return 0, parsing.At(i+1, err)
+		}
 		t := BuildTriangle(s)
 		n := t.NextValue()
 		total += n
-		// fmt.Println(t)
-		// fmt.Println(n, total)
+		if ttriangle.V(2) {
+			ttriangle.Log("triangle", "t", t)
+		}
+		ttriangle.Log("next", "n", n, "total", total)
 	}
-	return total
+	return total, nil
 }
 
-func part2(lines []string) int {
+func part2(ctx context.Context, lines []string) (int, error) {
 	total := 0
-	for _, line := range lines {
-		s := BuildSequence(line)
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		s, err := BuildSequence(line)
+		if err != nil {
+			return 0, parsing.At(i+1, err)
+		}
 		t := BuildTriangle(s)
 		n := t.PrevValue()
 		total += n
-		// fmt.Println(t)
-		// fmt.Println(n, total)
+		if ttriangle.V(2) {
+			ttriangle.Log("triangle", "t", t)
+		}
+		ttriangle.Log("prev", "n", n, "total", total)
 	}
-	return total
+	return total, nil
 }
 
-func main() {
-	args := os.Args[1:]
-	name := "sample"
-	if len(args) > 0 {
-		name = args[0]
-	}
-	f, err := os.Open(fmt.Sprintf("./data/%s.txt", name))
-	if err != nil {
-		log.Fatal(err)
-	}
-	b, err := io.ReadAll(f)
-	if err != nil {
-		log.Fatal(err)
-	}
-	lines := strings.Split(string(b), "\n")
-	fmt.Println(part1(lines))
-	fmt.Println(part2(lines))
+func init() {
+	runner.Register(runner.Day{Number: 9, Part1: part1, Part2: part2})
 }
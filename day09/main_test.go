@@ -0,0 +1,41 @@
+package day09
+
+import (
+	"context"
+	"testing"
+)
+
+func Test_BuildSequence(t *testing.T) {
+	tests := []struct {
+		line    string
+		wantErr string
+	}{
+		{"0 3 6 9 12 15", ""},
+		{"7", ""},
+		{"", "col 1: no numbers"},
+		{"   ", "col 1: no numbers"},
+		{"1 2 x", `col 5: "x" is not a number`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			_, err := BuildSequence(tt.line)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("BuildSequence() error = %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("BuildSequence() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	lines := []string{"0 3 6 9 12 15", " \t", "10 13 16 21 30 45"}
+	if got, err := part1(context.Background(), lines); err != nil || got != 18+68 {
+		t.Errorf("part1() = %d, %v, want %d", got, err, 18+68)
+	}
+	if got, err := part2(context.Background(), lines); err != nil || got != -3+5 {
+		t.Errorf("part2() = %d, %v, want %d", got, err, -3+5)
+	}
+}
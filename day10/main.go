@@ -1,11 +1,15 @@
-package main
+package day10
 
 import (
+	"context"
 	"fmt"
-	"io"
-	"log"
-	"os"
+	"log/slog"
 	"strings"
+
+	"github.com/kentquirk/aoc2023/collections"
+	"github.com/kentquirk/aoc2023/grid"
+	"github.com/kentquirk/aoc2023/parsing"
+	"github.com/kentquirk/aoc2023/runner"
 )
 
 type cell struct {
@@ -13,10 +17,8 @@ type cell struct {
 	pipe    rune
 }
 
-type pipes [][]cell
-
-type coord struct {
-	row, col int
+type pipes struct {
+	*grid.Grid[cell]
 }
 
 type edge struct {
@@ -55,75 +57,83 @@ func (e edge) valid() bool {
 		return e.endpipe == 'J'
 	case 'L':
 		return e.endpipe == '7'
-	default:
-		panic("bad start pipe")
 	}
+	// add is only given edges that start with one of those
+	return false
 }
 
-func load(lines []string) (pipes, coord) {
-	p := make(pipes, len(lines))
-	var sloc coord
-	for i, line := range lines {
-		p[i] = make([]cell, len(line))
-		for j, c := range line {
-			p[i][j] = cell{pipe: c}
-			if c == 'S' {
-				sloc = coord{i, j}
-			}
+func load(lines []string) (pipes, grid.Point, error) {
+	g, err := grid.Parse(lines, func(r rune) cell { return cell{pipe: r} })
+	if err != nil {
+		return pipes{}, grid.Point{}, err
+	}
+	p := pipes{g}
+	var sloc grid.Point
+	starts := 0
+	for _, pt := range p.Points() {
+		switch p.At(pt).pipe {
+		case '|', '-', 'L', 'J', '7', 'F', '.':
+		case 'S':
+			sloc = pt
+			starts++
+		default:
+			return pipes{}, grid.Point{}, parsing.At(pt.Row+1, parsing.Errorf(pt.Col+1, "%q is not a pipe", p.At(pt).pipe))
 		}
 	}
-	return p, sloc
+	if starts != 1 {
+		return pipes{}, grid.Point{}, fmt.Errorf("want one start, found %d", starts)
+	}
+	return p, sloc, nil
+}
+
+// openings are the two sides of each pipe that connect to its neighbors.
+var openings = map[rune][2]grid.Point{
+	'|': {grid.Up, grid.Down},
+	'-': {grid.Left, grid.Right},
+	'F': {grid.Down, grid.Right},
+	'L': {grid.Up, grid.Right},
+	'J': {grid.Up, grid.Left},
+	'7': {grid.Down, grid.Left},
 }
 
-// follow returns the number of steps to follow the path from start to finish
-// start is the starting point
-// loc is the current location being evaluated
-// dir is the direction we are moving
-func (p pipes) follow(loc, dir coord, total int) int {
-	for {
-		cell := p[loc.row][loc.col]
+// follow returns the number of steps along the loop from loc, entered by
+// moving in direction dir, back to the start, or an error if the path leads
+// somewhere a loop can't go.
+func (p pipes) follow(loc, dir grid.Point) (int, error) {
+	seen := collections.NewSet[grid.Point]()
+	for steps := 1; ; steps++ {
+		if !p.In(loc) {
+			return 0, fmt.Errorf("the loop runs off the edge at %v", loc)
+		}
+		cell := p.At(loc)
+		if seen.Contains(loc) {
+			return 0, parsing.At(loc.Row+1, parsing.Errorf(loc.Col+1, "the loop crosses itself at %q", cell.pipe))
+		}
+		seen.Add(loc)
 		cell.visited = true
-		p[loc.row][loc.col] = cell
-		switch cell.pipe {
-		case '|':
-			if dir.row == -1 {
-				return p.follow(coord{loc.row - 1, loc.col}, coord{-1, 0}, total+1)
-			}
-			return p.follow(coord{loc.row + 1, loc.col}, coord{1, 0}, total+1)
-		case '-':
-			if dir.col == -1 {
-				return p.follow(coord{loc.row, loc.col - 1}, coord{0, -1}, total+1)
-			}
-			return p.follow(coord{loc.row, loc.col + 1}, coord{0, 1}, total+1)
-		case 'F':
-			if dir.col == -1 {
-				return p.follow(coord{loc.row + 1, loc.col}, coord{1, 0}, total+1)
-			}
-			return p.follow(coord{loc.row, loc.col + 1}, coord{0, 1}, total+1)
-		case 'L':
-			if dir.col == -1 {
-				return p.follow(coord{loc.row - 1, loc.col}, coord{-1, 0}, total+1)
-			}
-			return p.follow(coord{loc.row, loc.col + 1}, coord{0, 1}, total+1)
-		case 'J':
-			if dir.row == 1 {
-				return p.follow(coord{loc.row, loc.col - 1}, coord{0, -1}, total+1)
-			}
-			return p.follow(coord{loc.row - 1, loc.col}, coord{-1, 0}, total+1)
-		case '7':
-			if dir.row == -1 {
-				return p.follow(coord{loc.row, loc.col - 1}, coord{0, -1}, total+1)
-			}
-			return p.follow(coord{loc.row + 1, loc.col}, coord{1, 0}, total+1)
-		case 'S':
-			return total + 1
+		p.Set(loc, cell)
+		if cell.pipe == 'S' {
+			return steps, nil
+		}
+		ends, ok := openings[cell.pipe]
+		back := grid.Point{Row: -dir.Row, Col: -dir.Col}
+		switch {
+		case !ok:
+			return 0, parsing.At(loc.Row+1, parsing.Errorf(loc.Col+1, "the loop runs into %q", cell.pipe))
+		case ends[0] == back:
+			dir = ends[1]
+		case ends[1] == back:
+			dir = ends[0]
 		default:
-			panic(fmt.Sprintf("bad pipe %v %v %v", loc, dir, p[loc.row+dir.row][loc.col+dir.col]))
+			return 0, parsing.At(loc.Row+1, parsing.Errorf(loc.Col+1, "the loop runs into the side of %q", cell.pipe))
 		}
+		loc = loc.Add(dir)
 	}
 }
 
-func part1(lines []string) (int, int) {
+// solve finds the loop and returns both the distance to the farthest point
+// along it and the number of cells it encloses.
+func solve(lines []string) (int, int, error) {
 	const (
 		L = 1 << iota
 		R
@@ -140,49 +150,49 @@ func part1(lines []string) (int, int) {
 	}
 
 	var schar int
-	p, sloc := load(lines)
+	p, sloc, err := load(lines)
+	if err != nil {
+		return 0, 0, err
+	}
 	lens := make([]int, 0)
-	if sloc.row < len(p)-1 {
-		switch p[sloc.row+1][sloc.col].pipe {
-		case '|', 'J', 'L':
-			lens = append(lens, p.follow(coord{sloc.row + 1, sloc.col}, coord{1, 0}, 0))
-			schar += D
-		}
+	// the pipes that connect to the start from each side
+	starts := []struct {
+		dir   grid.Point
+		side  int
+		pipes string
+	}{
+		{grid.Down, D, "|JL"},
+		{grid.Right, R, "-J7"},
+		{grid.Up, U, "|F7"},
+		{grid.Left, L, "-FL"},
 	}
-	if sloc.col < len(p[0])-1 {
-		switch p[sloc.row][sloc.col+1].pipe {
-		case '-', 'J', '7':
-			lens = append(lens, p.follow(coord{sloc.row, sloc.col + 1}, coord{0, 1}, 0))
-			schar += R
+	for _, s := range starts {
+		// At is the zero cell off the edge, which matches none of these
+		if !strings.ContainsRune(s.pipes, p.At(sloc.Add(s.dir)).pipe) {
+			continue
 		}
-	}
-	if sloc.row > 0 {
-		switch p[sloc.row-1][sloc.col].pipe {
-		case '|', 'F', '7':
-			lens = append(lens, p.follow(coord{sloc.row - 1, sloc.col}, coord{-1, 0}, 0))
-			schar += U
+		n, err := p.follow(sloc.Add(s.dir), s.dir)
+		if err != nil {
+			return 0, 0, err
 		}
+		lens = append(lens, n)
+		schar += s.side
 	}
-	if sloc.col > 0 {
-		switch p[sloc.row][sloc.col-1].pipe {
-		case '-', 'F', 'L':
-			lens = append(lens, p.follow(coord{sloc.row, sloc.col - 1}, coord{0, -1}, 0))
-			schar += L
-		}
+	if len(lens) != 2 {
+		return 0, 0, fmt.Errorf("the start at %v connects to %d pipes, not 2", sloc, len(lens))
 	}
 	// just verify that we get the same distance around in each direction
-	fmt.Printf("S=%c\n", sm[schar])
-	for _, l := range lens {
-		fmt.Println(l)
-	}
+	slog.Debug("start", "pipe", string(sm[schar]), "lengths", lens)
 
-	p[sloc.row][sloc.col].pipe = sm[schar]
-	contained := p.Contained(true)
+	s := p.At(sloc)
+	s.pipe = sm[schar]
+	p.Set(sloc, s)
+	contained := p.Contained(slog.Default().Enabled(context.Background(), slog.LevelDebug))
 
-	return (lens[0] + 1) / 2, contained
+	return (lens[0] + 1) / 2, contained, nil
 }
 
-func printBox(c rune) {
+func printBox(sb *strings.Builder, c rune) {
 	m := map[rune]rune{
 		'|':  0x2502,
 		'-':  0x2500,
@@ -195,21 +205,24 @@ func printBox(c rune) {
 		'o':  0x2591,
 		'\n': '\n',
 	}
-	fmt.Printf("%c", m[c])
+	sb.WriteRune(m[c])
 }
 
+// Contained counts the cells inside the loop. If doPrint is set, it also
+// logs a picture of the loop with the inside cells marked.
 func (p pipes) Contained(doPrint bool) int {
+	var sb strings.Builder
 	print := func(r rune) {
 		if doPrint {
-			printBox(r)
+			printBox(&sb, r)
 		}
 	}
 	edges := make(edgelist)
 	count := 0
 	// build a list of edges
-	for r, row := range p {
+	for r := 0; r < p.Height(); r++ {
 		e := edge{}
-		for c, cell := range row {
+		for c, cell := range p.Row(r) {
 			if !cell.visited {
 				continue
 			}
@@ -236,8 +249,8 @@ func (p pipes) Contained(doPrint bool) int {
 	}
 
 	// now that we have the edges we can count inside
-	for r, row := range p {
-		for c, cell := range row {
+	for r := 0; r < p.Height(); r++ {
+		for c, cell := range p.Row(r) {
 			if !cell.visited {
 				if edges.inside(r, c) {
 					count++
@@ -251,25 +264,22 @@ func (p pipes) Contained(doPrint bool) int {
 		}
 		print('\n')
 	}
+	if doPrint {
+		slog.Debug("enclosed", "count", count, "map", sb.String())
+	}
 	return count
 }
 
-func main() {
-	args := os.Args[1:]
-	name := "sample"
-	if len(args) > 0 {
-		name = args[0]
-	}
-	f, err := os.Open(fmt.Sprintf("./data/%s.txt", name))
-	if err != nil {
-		log.Fatal(err)
-	}
-	b, err := io.ReadAll(f)
-	if err != nil {
-		log.Fatal(err)
-	}
-	lines := strings.Split(string(b), "\n")
-	n, cont := part1(lines)
-	fmt.Println(n)
-	fmt.Println(cont)
+func part1(ctx context.Context, lines []string) (int, error) {
+	n, _, err := solve(lines)
+	return n, err
+}
+
+func part2(ctx context.Context, lines []string) (int, error) {
+	_, contained, err := solve(lines)
+	return contained, err
+}
+
+func init() {
+	runner.Register(runner.Day{Number: 10, Part1: part1, Part2: part2})
 }
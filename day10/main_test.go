@@ -0,0 +1,36 @@
+package day10
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kentquirk/aoc2023/parsing"
+)
+
+func Test_solveErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		lines     []string
+		line, col int
+	}{
+		{"dead end", []string{"S-7", "|.|", "L-."}, 3, 3},
+		{"not a pipe", []string{"S-7", "|x|", "L-J"}, 2, 2},
+		{"ragged", []string{"S-7", "||", "L-J"}, 2, 0},
+		{"into the side", []string{"S-F7", "..LJ"}, 1, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := solve(tt.lines)
+			var pe *parsing.Error
+			if !errors.As(err, &pe) {
+				t.Fatalf("solve() = %v, want a *parsing.Error", err)
+			}
+			if pe.Line != tt.line || pe.Col != tt.col {
+				t.Errorf("solve() error at %d:%d, want %d:%d (%v)", pe.Line, pe.Col, tt.line, tt.col, err)
+			}
+		})
+	}
+	if _, _, err := solve([]string{"S.", ".."}); err == nil {
+		t.Error("solve() with nothing connected to the start should fail")
+	}
+}
@@ -1,62 +1,66 @@
-package main
+package day11
 
 import (
-	"fmt"
-	"io"
-	"log"
-	"os"
-	"strings"
+	"context"
+	"github.com/kentquirk/aoc2023/grid"
+	"github.com/kentquirk/aoc2023/parsing"
+	"github.com/kentquirk/aoc2023/runner"
 )
 
-type loc struct {
-	row int
-	col int
-}
-
 type starmap struct {
-	width      int
-	height     int
-	stars      []loc
+	stars      []grid.Point
 	rowoffsets map[int]int
 	coloffsets map[int]int
 }
 
-func load(lines []string) *starmap {
+func empty(cells []rune) bool {
+	for _, c := range cells {
+		if c == '#' {
+			return false
+		}
+	}
+	return true
+}
+
+// load finds the stars, and for each row and column with stars in it, how
+// many empty ones come before it.
+func load(lines []string) (*starmap, error) {
+	g, err := grid.Parse(lines, func(r rune) rune { return r })
+	if err != nil {
+		return nil, err
+	}
 	m := &starmap{
-		width:      0,
-		height:     len(lines),
-		stars:      make([]loc, 0),
+		stars:      make([]grid.Point, 0),
 		rowoffsets: make(map[int]int),
 		coloffsets: make(map[int]int),
 	}
-	for i, line := range lines {
-		m.width = len(line)
-		for j, c := range line {
-			if c == '#' {
-				m.stars = append(m.stars, loc{row: i, col: j})
-				m.rowoffsets[i] = 0
-				m.coloffsets[j] = 0
-			}
+	for _, p := range g.Points() {
+		switch g.At(p) {
+		case '#':
+			m.stars = append(m.stars, p)
+		case '.':
+		default:
+			return nil, parsing.At(p.Row+1, parsing.Errorf(p.Col+1, "%q is not a star or space", g.At(p)))
 		}
 	}
 
 	offset := 0
-	for r := 0; r < m.height; r++ {
-		if _, ok := m.rowoffsets[r]; !ok {
+	for r := 0; r < g.Height(); r++ {
+		if empty(g.Row(r)) {
 			offset++
 		} else {
 			m.rowoffsets[r] = offset
 		}
 	}
 	offset = 0
-	for c := 0; c < m.width; c++ {
-		if _, ok := m.coloffsets[c]; !ok {
+	for c := 0; c < g.Width(); c++ {
+		if empty(g.Column(c)) {
 			offset++
 		} else {
 			m.coloffsets[c] = offset
 		}
 	}
-	return m
+	return m, nil
 }
 
 func (m *starmap) calcTotalDistance(multiplier int) int {
@@ -70,8 +74,8 @@ func (m *starmap) calcTotalDistance(multiplier int) int {
 	totalDistance := 0
 	for i := 0; i < len(m.stars)-1; i++ {
 		for j := i + 1; j < len(m.stars); j++ {
-			rowdist := abs((m.stars[i].row + m.rowoffsets[m.stars[i].row]*multiplier) - (m.stars[j].row + m.rowoffsets[m.stars[j].row]*multiplier))
-			coldist := abs((m.stars[i].col + m.coloffsets[m.stars[i].col]*multiplier) - (m.stars[j].col + m.coloffsets[m.stars[j].col]*multiplier))
+			rowdist := abs((m.stars[i].Row + m.rowoffsets[m.stars[i].Row]*multiplier) - (m.stars[j].Row + m.rowoffsets[m.stars[j].Row]*multiplier))
+			coldist := abs((m.stars[i].Col + m.coloffsets[m.stars[i].Col]*multiplier) - (m.stars[j].Col + m.coloffsets[m.stars[j].Col]*multiplier))
 			dist := rowdist + coldist
 			totalDistance += dist
 		}
@@ -79,31 +83,22 @@ func (m *starmap) calcTotalDistance(multiplier int) int {
 	return totalDistance
 }
 
-func part1(lines []string) int {
-	sm := load(lines)
-	return sm.calcTotalDistance(1)
-}
-
-func part2(lines []string) int {
-	sm := load(lines)
-	return sm.calcTotalDistance(999_999)
-}
-
-func main() {
-	args := os.Args[1:]
-	name := "sample"
-	if len(args) > 0 {
-		name = args[0]
-	}
-	f, err := os.Open(fmt.Sprintf("./data/%s.txt", name))
+func part1(ctx context.Context, lines []string) (int, error) {
+	sm, err := load(lines)
 	if err != nil {
-		log.Fatal(err)
+		return 0, err
 	}
-	b, err := io.ReadAll(f)
+	return sm.calcTotalDistance(1), nil
+}
+
+func part2(ctx context.Context, lines []string) (int, error) {
+	sm, err := load(lines)
 	if err != nil {
-		log.Fatal(err)
+		return 0, err
 	}
-	lines := strings.Split(string(b), "\n")
-	fmt.Println(part1(lines))
-	fmt.Println(part2(lines))
+	return sm.calcTotalDistance(999_999), nil
+}
+
+func init() {
+	runner.Register(runner.Day{Number: 11, Part1: part1, Part2: part2})
 }
@@ -1,5 +0,0 @@
-module github.com/kentquirk/aoc2023/XXX
-
-go 1.20
-
-require github.com/dgryski/go-wyhash v0.0.0-20191203203029-c4841ae36371 // indirect
@@ -1,15 +1,18 @@
-package main
+package day12
 
 import (
 	"bytes"
+	"context"
 	"fmt"
-	"io"
-	"log"
-	"os"
-	"strconv"
+	"slices"
 	"strings"
 
-	"github.com/dgryski/go-wyhash"
+	"github.com/kentquirk/aoc2023/collections"
+	"github.com/kentquirk/aoc2023/memo"
+	"github.com/kentquirk/aoc2023/parsing"
+	"github.com/kentquirk/aoc2023/progress"
+	"github.com/kentquirk/aoc2023/runner"
+	"github.com/kentquirk/aoc2023/trace"
 )
 
 type grouplist []int
@@ -35,37 +38,37 @@ func (g grouplist) MinLen() int {
 
 type memofunc func(grouplist, int) int
 
-func hash(groups grouplist, start int) uint64 {
-	var seed uint64 = 253295235
-	b := make([]byte, len(groups)+1)
-	for i, g := range groups {
-		b[i] = byte(g)
-	}
-	b[len(b)-1] = byte(start)
-	return wyhash.Hash(b, seed)
+type args struct {
+	groups grouplist
+	start  int
+}
+
+type key struct {
+	ngroups int
+	start   int
 }
 
-func memoize(cache map[uint64]int, f memofunc) memofunc {
+// The groups passed around are always a tail of the row's groups, so their
+// length is enough to tell them apart.
+func (a args) key() key {
+	return key{len(a.groups), a.start}
+}
+
+func memoize(cache *memo.Cache[key, int], f memofunc) memofunc {
+	mf := memo.Keyed(cache, args.key, func(a args) int { return f(a.groups, a.start) })
 	return func(groups grouplist, start int) int {
-		h := hash(groups, start)
-		v, ok := cache[h]
-		if ok {
-			return v
-		}
-		v = f(groups, start)
-		cache[h] = v
-		return v
+		return mf(args{groups, start})
 	}
 }
 
 type row struct {
 	source       []byte
-	possibleLocs map[int]struct{}
+	possibleLocs collections.Set[int]
 	groups       grouplist
 	caf          memofunc
 	caa          memofunc
-	cafCache     map[uint64]int
-	caaCache     map[uint64]int
+	cafCache     *memo.Cache[key, int]
+	caaCache     *memo.Cache[key, int]
 }
 
 func groups(b []byte) []int {
@@ -97,44 +100,66 @@ func (r *row) isValid(arrangement []byte) bool {
 	return true
 }
 
-func NewRow(line string, count int) *row {
-	splits := strings.Split(line, " ")
+func NewRow(line string, count int) (*row, error) {
+	splits, err := parsing.Fields(line, 2)
+	if err != nil {
+		return nil, err
+	}
+	if i := strings.IndexFunc(splits[0], func(r rune) bool { return !strings.ContainsRune(".#?", r) }); i >= 0 {
+		return nil, parsing.Errorf(i+1, "%q is not a spring", splits[0][i])
+	}
+	var groups grouplist
+	col := len(splits[0]) + 1
+	for _, num := range strings.Split(splits[1], ",") {
+		n, err := parsing.Int(num)
+		if err == nil && n < 1 {
+			err = parsing.Errorf(1, "group size %d is not positive", n)
+		}
+		if err != nil {
+			return nil, parsing.Offset(col, err)
+		}
+		groups = append(groups, n)
+		col += len(num) + 1
+	}
 	src := splits[0]
-	grps := splits[1]
+	grps := slices.Clone(groups)
 	for i := 1; i < count; i++ {
 		src += "?" + splits[0]
-		grps += "," + splits[1]
+		grps = append(grps, groups...)
 	}
 	r := &row{
 		source:       []byte(src),
-		groups:       make(grouplist, 0),
-		possibleLocs: make(map[int]struct{}),
-		cafCache:     make(map[uint64]int),
-		caaCache:     make(map[uint64]int),
+		groups:       grps,
+		possibleLocs: make(collections.Set[int]),
+		cafCache:     memo.New[key, int](0),
+		caaCache:     memo.New[key, int](0),
 	}
 	r.caf = memoize(r.cafCache, r.countArrangementsFrom)
 	r.caa = memoize(r.caaCache, r.countAllArrangements)
 
 	for i, b := range r.source {
 		if b != '.' {
-			r.possibleLocs[i] = struct{}{}
+			r.possibleLocs.Add(i)
 		}
 	}
 
-	for _, num := range strings.Split(grps, ",") {
-		n, _ := strconv.Atoi(num)
-		r.groups = append(r.groups, n)
-	}
-	return r
+	return r, nil
 }
 
 func (r *row) String() string {
-	return fmt.Sprintf("%s %v (p%v)", string(r.source), r.groups, r.possibleLocs)
+	return fmt.Sprintf("%s %v (p%v)", string(r.source), r.groups, collections.Sorted(r.possibleLocs))
 }
 
+var (
+	// tcaf traces countArrangementsFrom; at verbosity 2 it shows each
+	// location it checks as well.
+	tcaf = trace.New("day12", "caf")
+	trow = trace.New("day12", "row")
+)
+
 // return the number of valid arrangements for the groups, anchored at start
 func (r *row) countArrangementsFrom(groups grouplist, start int) int {
-	// fmt.Printf("countArrangements(%v, %d)\n", groups, start)
+	tcaf.Printf("countArrangements(%v, %d)", groups, start)
 	if len(groups) == 0 {
 		// check that the rest of the row is not required
 		for i := start; i < len(r.source); i++ {
@@ -144,8 +169,7 @@ func (r *row) countArrangementsFrom(groups grouplist, start int) int {
 		}
 		return 1
 	}
-	_, ok := r.possibleLocs[start]
-	if !ok {
+	if !r.possibleLocs.Contains(start) {
 		return 0
 	}
 	if start+groups.MinLen() > len(r.source) {
@@ -154,7 +178,9 @@ func (r *row) countArrangementsFrom(groups grouplist, start int) int {
 	// have a potential start location
 	for i := 0; i < groups[0]; i++ {
 		// check that the next i locations are valid
-		// fmt.Printf("checking %d: %s\n", start, string(r.source[start:start+i]))
+		if tcaf.V(2) {
+			tcaf.Printf("checking %d: %s", start, string(r.source[start:start+i]))
+		}
 		if r.source[start+i] == '.' {
 			return 0
 		}
@@ -164,7 +190,7 @@ func (r *row) countArrangementsFrom(groups grouplist, start int) int {
 		return 0
 	}
 	// it's valid, so recurse with the next group
-	// fmt.Println(string(r.source), groups, start)
+	tcaf.Printf("valid: %s %v %d", string(r.source), groups, start)
 	return r.caa(groups[1:], start+groups[0]+1)
 }
 
@@ -186,33 +212,38 @@ func (r *row) countAllArrangements(groups grouplist, start int) int {
 	return total
 }
 
-func solve(lines []string, count int) int {
+func solve(ctx context.Context, lines []string, count int) (int, error) {
 	total := 0
-	for _, line := range lines {
-		r := NewRow(line, count)
-		// fmt.Println(r)
+	rows := progress.Start(ctx, "rows", len(lines))
+	defer rows.Done()
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		r, err := NewRow(line, count)
+		if err != nil {
+			return 0, parsing.At(i+1, err)
+		}
+		trow.Log("row", "row", r)
 		arr := r.caa(r.groups, 0)
-		// fmt.Printf("%s: %d\n", line, arr)
+		trow.Log("arrangements", "line", line, "count", arr, "caf", r.cafCache.Stats(), "caa", r.caaCache.Stats())
 		total += arr
+		rows.Set(i + 1)
 	}
-	return total
+	return total, nil
 }
 
-func main() {
-	args := os.Args[1:]
-	name := "sample"
-	if len(args) > 0 {
-		name = args[0]
-	}
-	f, err := os.Open(fmt.Sprintf("./data/%s.txt", name))
-	if err != nil {
-		log.Fatal(err)
-	}
-	b, err := io.ReadAll(f)
-	if err != nil {
-		log.Fatal(err)
-	}
-	lines := strings.Split(string(b), "\n")
-	fmt.Println(solve(lines, 1))
-	fmt.Println(solve(lines, 5))
+func part1(ctx context.Context, lines []string) (int, error) {
+	return solve(ctx, lines, 1)
+}
+
+func part2(ctx context.Context, lines []string) (int, error) {
+	return solve(ctx, lines, 5)
+}
+
+func init() {
+	runner.Register(runner.Day{Number: 12, Part1: part1, Part2: part2})
 }
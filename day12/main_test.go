@@ -1,4 +1,4 @@
-package main
+package day12
 
 import (
 	"fmt"
@@ -54,8 +54,10 @@ func Test_all(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(fmt.Sprintf("%s/%d", tt.s, tt.count), func(t *testing.T) {
-			r := NewRow(tt.s, tt.count)
-			// fmt.Println(r)
+			r, err := NewRow(tt.s, tt.count)
+			if err != nil {
+				t.Fatal(err)
+			}
 			if got := r.caa(r.groups, 0); got != tt.want {
 				t.Errorf("n = %v, want %v", got, tt.want)
 			}
@@ -1,90 +1,82 @@
-package main
+package day13
 
 import (
+	"context"
 	"fmt"
-	"io"
-	"log"
-	"os"
-	"strings"
+	"log/slog"
+	"math/bits"
+
+	"github.com/kentquirk/aoc2023/grid"
+	"github.com/kentquirk/aoc2023/parsing"
+	"github.com/kentquirk/aoc2023/runner"
+	"github.com/kentquirk/aoc2023/trace"
 )
 
-func bstr(i, l int) string {
-	s := ""
-	b := 1 << uint(l-1)
-	for b != 0 {
-		if (b & i) == 0 {
-			s += "."
-		} else {
-			s += "#"
+// block is a pattern, with each row also packed into an int, first column
+// highest, so rows can be compared (and XORed) in one go.
+type block struct {
+	g     *grid.Grid[byte]
+	data  []int
+	score int
+}
+
+func pack(cells []byte) int {
+	n := 0
+	for _, c := range cells {
+		n <<= 1
+		if c == '#' {
+			n |= 1
 		}
-		b >>= 1
 	}
-	return s
+	return n
 }
 
-type block struct {
-	width  int
-	height int
-	data   []int
-	score  int
-}
-
-func loadBlock(s []string) block {
-	var b block
-	b.height = len(s)
-	for _, line := range s {
-		b.width = len(line)
-		n := 0
-		for i := 0; i < b.width; i++ {
-			c := line[i]
-			if c == '#' {
-				n |= (1 << uint(b.width-i-1))
-			}
-		}
-		b.data = append(b.data, n)
+func newBlock(g *grid.Grid[byte]) block {
+	b := block{g: g}
+	for r := 0; r < g.Height(); r++ {
+		b.data = append(b.data, pack(g.Row(r)))
 	}
 	return b
 }
 
-func (b block) String() string {
-	s := fmt.Sprintf("%d x %d (%d)\n", b.width, b.height, b.score)
-	for _, row := range b.data {
-		s += bstr(row, b.width) + "\n"
+func loadBlock(b parsing.Block) (block, error) {
+	g, err := grid.Parse(b.Lines, func(r rune) byte { return byte(r) })
+	if err != nil {
+		return block{}, b.At(0, err)
+	}
+	for _, p := range g.Points() {
+		if c := g.At(p); c != '#' && c != '.' {
+			return block{}, b.At(p.Row, parsing.Errorf(p.Col+1, "%q is not ash or rock", c))
+		}
 	}
-	return s
+	return newBlock(g), nil
 }
 
-func (b block) clone() block {
-	var nb block
-	nb.width = b.width
-	nb.height = b.height
-	nb.data = append([]int(nil), b.data...)
-	return nb
+func (b block) String() string {
+	return fmt.Sprintf("%d x %d (%d)\n%s", b.g.Width(), b.g.Height(), b.score, b.g)
 }
 
-func (b block) column(c int) int {
-	n := 0
-	bit := 1 << (b.width - c - 1)
-	for row := 0; row < b.height; row++ {
-		if b.data[row]&bit != 0 {
-			n |= (1 << uint(b.height-row-1))
-		}
+// flip returns a copy of the block with the cell at p changed.
+func (b block) flip(p grid.Point) block {
+	g := b.g.Clone()
+	if g.At(p) == '#' {
+		g.Set(p, '.')
+	} else {
+		g.Set(p, '#')
 	}
-	return n
+	return newBlock(g)
+}
+
+func (b block) column(c int) int {
+	return pack(b.g.Column(c))
 }
 
 func (b block) rotate() block {
-	var nb block
-	nb.width = b.height
-	nb.height = b.width
-	for c := 0; c < b.width; c++ {
-		nb.data = append(nb.data, b.column(c))
-	}
-	return nb
+	return newBlock(b.g.Transpose())
 }
 
 func (b block) checkEqualRows(r1, r2 int) bool {
-	if r1 < 0 || r2 >= b.height {
+	if r1 < 0 || r2 >= b.g.Height() {
 		return true
 	}
 	if b.data[r1] == b.data[r2] {
@@ -94,7 +86,7 @@ func (b block) checkEqualRows(r1, r2 int) bool {
 }
 
 func (b block) checkEqualColumns(c1, c2 int) bool {
-	if c1 < 0 || c2 >= b.width {
+	if c1 < 0 || c2 >= b.g.Width() {
 		return true
 	}
 	if b.column(c1) == b.column(c2) {
@@ -104,7 +96,7 @@ func (b block) checkEqualColumns(c1, c2 int) bool {
 }
 
 func (b block) findHorizontalReflection(old int) (int, bool) {
-	for r := 0; r < b.height-1; r++ {
+	for r := 0; r < b.g.Height()-1; r++ {
 		if b.checkEqualRows(r, r+1) {
 			if r+1 == old {
 				// it's the same one, try again
@@ -117,7 +109,7 @@ func (b block) findHorizontalReflection(old int) (int, bool) {
 }
 
 func (b block) findVerticalReflection() (int, bool) {
-	for c := 0; c < b.width-1; c++ {
+	for c := 0; c < b.g.Width()-1; c++ {
 		if b.checkEqualColumns(c, c+1) {
 			return c + 1, true
 		}
@@ -125,23 +117,29 @@ func (b block) findVerticalReflection() (int, bool) {
 	return 0, false
 }
 
-func part1(blocks []block) int {
+var (
+	tblock  = trace.New("day13", "block")
+	tsmudge = trace.New("day13", "smudge")
+)
+
+// scoreReflections records the score of each block's reflection and returns the total.
+func scoreReflections(blocks []block) int {
 	total := 0
 	for i, block := range blocks {
-		// fmt.Printf("Block %d:\n%s\n", i, block)
+		tblock.Log("block", "n", i, "block", block)
 		if r, ok := block.findHorizontalReflection(-1); ok {
-			// fmt.Println("Reflection found in block ", i, "at row", r)
+			tblock.Log("reflection", "block", i, "row", r)
 			blocks[i].score = 100 * r
 			total += blocks[i].score
 			continue
 		}
 		if c, ok := block.findVerticalReflection(); ok {
-			// fmt.Println("Reflection found in block ", i, "at col", c)
+			tblock.Log("reflection", "block", i, "col", c)
 			blocks[i].score = c
 			total += blocks[i].score
 			continue
 		}
-		fmt.Println("No reflection found in block ", i)
+		slog.Warn("no reflection found", "block", i)
 	}
 
 	return total
@@ -150,17 +148,16 @@ func part1(blocks []block) int {
 func (b block) findNewHorizontalReflection(old int) (int, bool) {
 	// XOR all pairs of rows together and count bits; if there's 1 bit then we can flip it and
 	// try to see if we have a valid reflection
-	for r1 := 0; r1 < b.height-1; r1++ {
-		for r2 := r1 + 1; r2 < b.height; r2++ {
+	for r1 := 0; r1 < b.g.Height()-1; r1++ {
+		for r2 := r1 + 1; r2 < b.g.Height(); r2++ {
 			x := b.data[r1] ^ b.data[r2]
 			// fast way to tell if a number is a power of 2 (has only 1 bit set)
 			if (x != 0) && (x&(x-1) == 0) {
 				// We have a pair of rows that differ by 1 bit; try flipping one of them
 				// and see if we have a valid reflection
-				nb := b.clone()
-				nb.data[r1] ^= x
+				nb := b.flip(grid.Point{Row: r1, Col: b.g.Width() - 1 - bits.TrailingZeros(uint(x))})
 				if r, ok := nb.findHorizontalReflection(old); ok {
-					// fmt.Printf("FHR\n%s\n", nb)
+					tsmudge.Log("new horizontal reflection", "row", r, "block", nb)
 					return r, true
 				}
 			}
@@ -169,49 +166,60 @@ func (b block) findNewHorizontalReflection(old int) (int, bool) {
 	return 0, false
 }
 
-func part2(blocks []block) int {
+func scoreNewReflections(blocks []block) int {
 	total := 0
 	for i, block := range blocks {
-		fmt.Printf("Block %d:\n%s\n", i, block)
+		slog.Debug("block", "n", i, "data", block)
 
 		if r, ok := block.findNewHorizontalReflection(block.score / 100); ok {
-			fmt.Println("New reflection found in block ", i, "for score ", r*100)
+			slog.Debug("new reflection", "block", i, "score", r*100)
 			total += 100 * r
 			continue
 		}
 
 		nb := block.rotate()
 		if r, ok := nb.findNewHorizontalReflection(block.score); ok {
-			fmt.Println("New reflection found in block ", i, "for score ", r)
+			slog.Debug("new reflection", "block", i, "score", r)
 			total += r
 			continue
 		}
 
-		fmt.Println("No new reflection found in block ", i)
+		slog.Warn("no new reflection found", "block", i)
 	}
 
 	return total
 }
 
-func main() {
-	args := os.Args[1:]
-	name := "sample7"
-	if len(args) > 0 {
-		name = args[0]
+func loadBlocks(lines []string) ([]block, error) {
+	blocks := make([]block, 0)
+	for _, s := range parsing.Blocks(lines) {
+		b, err := loadBlock(s)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, b)
 	}
-	f, err := os.Open(fmt.Sprintf("./data/%s.txt", name))
+	return blocks, nil
+}
+
+func part1(ctx context.Context, lines []string) (int, error) {
+	blocks, err := loadBlocks(lines)
 	if err != nil {
-		log.Fatal(err)
+		return 0, err
 	}
-	b, err := io.ReadAll(f)
+	return scoreReflections(blocks), nil
+}
+
+// part2 needs the old reflections so it can skip them
+func part2(ctx context.Context, lines []string) (int, error) {
+	blocks, err := loadBlocks(lines)
 	if err != nil {
-		log.Fatal(err)
+		return 0, err
 	}
-	sections := strings.Split(string(b), "\n\n")
-	blocks := make([]block, 0)
-	for _, s := range sections {
-		blocks = append(blocks, loadBlock(strings.Split(s, "\n")))
-	}
-	fmt.Printf("Part 1: %d\n", part1(blocks))
-	fmt.Printf("Part 2: %d\n", part2(blocks))
+	scoreReflections(blocks)
+	return scoreNewReflections(blocks), nil
+}
+
+func init() {
+	runner.Register(runner.Day{Number: 13, Part1: part1, Part2: part2})
 }
@@ -0,0 +1,37 @@
+package day13
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kentquirk/aoc2023/parsing"
+)
+
+func Test_loadBlocksErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		lines     []string
+		line, col int
+		want      string
+	}{
+		{"not ash or rock", []string{"#.#", "#x#", "", "##"}, 2, 2, "'x' is not ash or rock"},
+		{"in a later block", []string{"#.#", "", ".#.", "#.#", "", "##", ".x"}, 7, 2, "'x' is not ash or rock"},
+		{"ragged", []string{"#.#", "##"}, 2, 0, "2 wide, want 3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadBlocks(tt.lines)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("loadBlocks() error = %v, want one saying %q", err, tt.want)
+			}
+			var pe *parsing.Error
+			if !errors.As(err, &pe) {
+				t.Fatalf("loadBlocks() = %v, want a *parsing.Error", err)
+			}
+			if pe.Line != tt.line || pe.Col != tt.col {
+				t.Errorf("loadBlocks() error at %d:%d, want %d:%d (%v)", pe.Line, pe.Col, tt.line, tt.col, err)
+			}
+		})
+	}
+}
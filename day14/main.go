@@ -1,11 +1,14 @@
-package main
+package day14
 
 import (
-	"fmt"
-	"io"
-	"log"
-	"os"
-	"strings"
+	"context"
+	"log/slog"
+
+	"github.com/kentquirk/aoc2023/cycle"
+	"github.com/kentquirk/aoc2023/grid"
+	"github.com/kentquirk/aoc2023/parsing"
+	"github.com/kentquirk/aoc2023/progress"
+	"github.com/kentquirk/aoc2023/runner"
 )
 
 type cell byte
@@ -16,48 +19,39 @@ const (
 	round cell = 'O'
 )
 
+// dish is the platform, with the load on the north beams after each spin
+// cycle so far.
 type dish struct {
-	width  int
-	height int
-	data   [][]cell
-	loads  []int
+	*grid.Grid[cell]
+	loads []int
 }
 
-func parse(lines []string) *dish {
-	d := &dish{
-		width:  len(lines[0]),
-		height: len(lines),
-		data:   make([][]cell, len(lines)),
+func parse(lines []string) (*dish, error) {
+	g, err := grid.Parse(lines, func(r rune) cell { return cell(r) })
+	if err != nil {
+		return nil, err
 	}
-	for i, line := range lines {
-		d.data[i] = make([]cell, len(line))
-		for j, c := range line {
-			d.data[i][j] = cell(c)
+	for _, p := range g.Points() {
+		switch c := g.At(p); c {
+		case empty, cube, round:
+		default:
+			return nil, parsing.At(p.Row+1, parsing.Errorf(p.Col+1, "%q is not a rock or space", c))
 		}
 	}
+	d := &dish{Grid: g}
 	d.loads = append(d.loads, d.calcLoad())
-	return d
-}
-
-func (d *dish) String() string {
-	var sb strings.Builder
-	for _, line := range d.data {
-		for _, c := range line {
-			sb.WriteByte(byte(c))
-		}
-		sb.WriteByte('\n')
-	}
-	return sb.String()
+	return d, nil
 }
 
 func (d *dish) tiltNorth() {
 	for {
 		nmoved := 0
-		for row := 1; row < d.height; row++ {
-			for col := 0; col < d.width; col++ {
-				if d.data[row][col] == round && d.data[row-1][col] == empty {
-					d.data[row-1][col] = round
-					d.data[row][col] = empty
+		for row := 1; row < d.Height(); row++ {
+			cur, next := d.Row(row), d.Row(row-1)
+			for col := 0; col < d.Width(); col++ {
+				if cur[col] == round && next[col] == empty {
+					next[col] = round
+					cur[col] = empty
 					nmoved++
 				}
 			}
@@ -71,11 +65,12 @@ func (d *dish) tiltNorth() {
 func (d *dish) tiltSouth() {
 	for {
 		nmoved := 0
-		for row := d.height - 2; row >= 0; row-- {
-			for col := 0; col < d.width; col++ {
-				if d.data[row][col] == round && d.data[row+1][col] == empty {
-					d.data[row+1][col] = round
-					d.data[row][col] = empty
+		for row := d.Height() - 2; row >= 0; row-- {
+			cur, next := d.Row(row), d.Row(row+1)
+			for col := 0; col < d.Width(); col++ {
+				if cur[col] == round && next[col] == empty {
+					next[col] = round
+					cur[col] = empty
 					nmoved++
 				}
 			}
@@ -89,11 +84,11 @@ func (d *dish) tiltSouth() {
 func (d *dish) tiltWest() {
 	for {
 		nmoved := 0
-		for col := 1; col < d.width; col++ {
-			for row := 0; row < d.height; row++ {
-				if d.data[row][col] == round && d.data[row][col-1] == empty {
-					d.data[row][col-1] = round
-					d.data[row][col] = empty
+		for col := 1; col < d.Width(); col++ {
+			for row := 0; row < d.Height(); row++ {
+				if r := d.Row(row); r[col] == round && r[col-1] == empty {
+					r[col-1] = round
+					r[col] = empty
 					nmoved++
 				}
 			}
@@ -107,11 +102,11 @@ func (d *dish) tiltWest() {
 func (d *dish) tiltEast() {
 	for {
 		nmoved := 0
-		for col := d.width - 2; col >= 0; col-- {
-			for row := 0; row < d.height; row++ {
-				if d.data[row][col] == round && d.data[row][col+1] == empty {
-					d.data[row][col+1] = round
-					d.data[row][col] = empty
+		for col := d.Width() - 2; col >= 0; col-- {
+			for row := 0; row < d.Height(); row++ {
+				if r := d.Row(row); r[col] == round && r[col+1] == empty {
+					r[col+1] = round
+					r[col] = empty
 					nmoved++
 				}
 			}
@@ -129,123 +124,54 @@ func (d *dish) cycle() {
 	d.tiltEast()
 }
 
-func (d *dish) getLoadForCycle(n int) int {
-	if n < len(d.loads) {
-		return d.loads[n]
-	}
-	for i := len(d.loads); i <= n; i++ {
-		d.cycle()
-		d.loads = append(d.loads, d.calcLoad())
-	}
-	return d.loads[n]
-}
-
 func (d *dish) calcLoad() int {
 	load := 0
-	for row := 0; row < d.height; row++ {
-		for col := 0; col < d.width; col++ {
-			if d.data[row][col] == round {
-				load += d.height - row
+	for row := 0; row < d.Height(); row++ {
+		for _, c := range d.Row(row) {
+			if c == round {
+				load += d.Height() - row
 			}
 		}
 	}
 	return load
 }
 
-func part1(lines []string) int {
-	d := parse(lines)
-	fmt.Println(d)
-	fmt.Println()
+func part1(ctx context.Context, lines []string) (int, error) {
+	d, err := parse(lines)
+	if err != nil {
+		return 0, err
+	}
+	slog.Debug("before tilting", "dish", d)
 	d.tiltNorth()
-	fmt.Println(d)
-	return d.calcLoad()
+	slog.Debug("tilted north", "dish", d)
+	return d.calcLoad(), nil
 }
 
-// We need to find a cycle in the iterations of the dish.
-// The normal cycle detection algorithms don't work here because
-// the values are not unique and we need to make sure that the cycle
-// is long enough.
-
-// So we're going to grab a sequence of values and count the number of occurrences of each value within it.
-// As long as all of them have a count more than 1, we probably are likely to contain a cycle within it,
-// so we choose a value that has the least count, look for two occurrences of it, and then check if that's a cycle.
-
-// If not, we grab a longer chunk and repeat.
-
-func findCycle(d *dish) (int, int) {
-	chunksize := 100
-	start := 100
-	minload := 0
-	mincount := 0
-	for {
-		loads := make(map[int]int)
-		for i := 0; i < chunksize; i++ {
-			loads[d.getLoadForCycle(i+start)]++
-		}
-		for load, count := range loads {
-			if mincount == 0 || count < mincount {
-				minload = load
-				mincount = count
-			}
-		}
-		if mincount == 1 {
-			start += chunksize
-			chunksize *= 2
-			mincount = 0
-			// fmt.Println(loads)
-			fmt.Println("increasing start to", start)
-			fmt.Println("increasing chunk size to", chunksize)
-			continue
-		}
-		fmt.Println(minload, mincount)
-		break
+// part2 spins the dish until it gets back to a position it has been in
+// before, then works out where in that cycle the billionth spin leaves it.
+func part2(ctx context.Context, lines []string) (int, error) {
+	d, err := parse(lines)
+	if err != nil {
+		return 0, err
 	}
-	first, second := -1, -1
-	for i := start; i < start+chunksize; i++ {
-		if d.getLoadForCycle(i) == minload {
-			if first == -1 {
-				first = i
-			} else if second == -1 {
-				second = i
-				break
-			}
+	var det cycle.Detector[uint64]
+	spins := progress.Start(ctx, "spins", 0)
+	defer spins.Done()
+	for {
+		if err := ctx.Err(); err != nil {
+			return 0, err
 		}
-	}
-	// now verify that this is a cycle
-	for i := first; i < second; i++ {
-		if d.getLoadForCycle(i) != d.getLoadForCycle(i+second-first) {
-			fmt.Println("not a real cycle")
+		spins.Add(1)
+		c, ok := det.Add(d.Hash())
+		if ok {
+			slog.Debug("cycle", "length", c.Period, "start", c.Start)
+			return cycle.Extrapolate(c, d.loads, 1_000_000_000), nil
 		}
+		d.cycle()
+		d.loads = append(d.loads, d.calcLoad())
 	}
-	return second - first, first
 }
 
-func part2(lines []string) int {
-	d := parse(lines)
-	cyclelen, startindex := findCycle(d)
-	fmt.Println(cyclelen, startindex)
-	// now calculate the load for the 1_000_000_000 cycle
-	// we know that the cycle starts at startindex and has length cyclelen
-
-	ix := startindex + (1_000_000_000-startindex)%cyclelen
-	return d.getLoadForCycle(ix)
-}
-
-func main() {
-	args := os.Args[1:]
-	name := "sample"
-	if len(args) > 0 {
-		name = args[0]
-	}
-	f, err := os.Open(fmt.Sprintf("./data/%s.txt", name))
-	if err != nil {
-		log.Fatal(err)
-	}
-	b, err := io.ReadAll(f)
-	if err != nil {
-		log.Fatal(err)
-	}
-	lines := strings.Split(string(b), "\n")
-	// fmt.Println(part1(lines))
-	fmt.Println(part2(lines))
+func init() {
+	runner.Register(runner.Day{Number: 14, Part1: part1, Part2: part2})
 }
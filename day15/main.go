@@ -1,12 +1,14 @@
-package main
+package day15
 
 import (
-	"fmt"
-	"io"
-	"log"
-	"os"
+	"context"
 	"regexp"
 	"strings"
+	"unicode"
+
+	"github.com/kentquirk/aoc2023/parsing"
+	"github.com/kentquirk/aoc2023/runner"
+	"github.com/kentquirk/aoc2023/trace"
 )
 
 type slot struct {
@@ -54,62 +56,100 @@ func HASH(s string) byte {
 	return h
 }
 
-func part1(steps []string) int {
+// A step is one of the comma-separated steps, and where it starts.
+type step struct {
+	text      string
+	line, col int
+}
+
+func part1(steps []step) int {
 	total := 0
 	for _, s := range steps {
-		total += int(HASH(s))
+		total += int(HASH(s.text))
 	}
 	return total
 }
 
-func part2(steps []string) int {
+// steppat is a label followed by either - or = and a focal length.
+var steppat = regexp.MustCompile(`^(?P<label>\w+)(?P<action>-|=)(?P<lens>\d+)?$`)
+
+// parseStep returns the label, action and focal length of s, which is 0
+// for a -.
+func parseStep(s string) (string, string, int, error) {
+	var st struct{ Label, Action, Lens string }
+	if err := parsing.Bind(steppat, s, &st); err != nil {
+		return "", "", 0, err
+	}
+	col := len(st.Label) + 2
+	switch {
+	case st.Action == "-" && st.Lens != "":
+		return "", "", 0, parsing.Errorf(col, "only = takes a focal length")
+	case st.Action == "-":
+		return st.Label, st.Action, 0, nil
+	case st.Lens == "":
+		return "", "", 0, parsing.Errorf(col-1, "= needs a focal length")
+	}
+	lens, err := parsing.Int(st.Lens)
+	if err == nil && (lens < 1 || lens > 9) {
+		err = parsing.Errorf(1, "focal length must be 1-9, not %s", st.Lens)
+	}
+	if err != nil {
+		return "", "", 0, parsing.Offset(col-1, err)
+	}
+	return st.Label, st.Action, lens, nil
+}
+
+func part2(steps []step) (int, error) {
 	boxes := make(map[byte]*box)
-	pat := regexp.MustCompile(`^(\w+)([=-])(\d)?$`)
 	for _, s := range steps {
-		parts := pat.FindStringSubmatch(s)
-		label := parts[1]
-		action := parts[2]
+		label, action, lens, err := parseStep(s.text)
+		if err != nil {
+			return 0, parsing.At(s.line, parsing.Offset(s.col-1, err))
+		}
 		bi := HASH(label)
 		b, ok := boxes[bi]
 		if !ok {
 			b = &box{}
 			boxes[bi] = b
 		}
-		switch action {
-		case "=":
-			lens := int(parts[3][0] - '0')
+		if action == "=" {
 			b.add(label, lens)
-		case "-":
+		} else {
 			b.remove(label)
-		default:
-			log.Fatalf("unknown action %s", action)
 		}
 	}
 
 	total := 0
 	for bi, b := range boxes {
-		// fmt.Printf("box %d: %v\n", bi, b)
+		tbox.Printf("box %d: %v", bi, b)
 		total += b.totalFocusingPower(bi)
 	}
-	return total
+	return total, nil
 }
 
-func main() {
-	args := os.Args[1:]
-	name := "sample"
-	if len(args) > 0 {
-		name = args[0]
-	}
-	f, err := os.Open(fmt.Sprintf("./data/%s.txt", name))
-	if err != nil {
-		log.Fatal(err)
-	}
-	b, err := io.ReadAll(f)
-	if err != nil {
-		log.Fatal(err)
+var tbox = trace.New("day15", "box")
+
+// split returns the steps, which are separated by commas and may run over
+// more than one line.
+func split(lines []string) []step {
+	var steps []step
+	for i, line := range lines {
+		line = strings.TrimRightFunc(line, unicode.IsSpace)
+		col := 1
+		for _, s := range strings.Split(line, ",") {
+			if s != "" {
+				steps = append(steps, step{text: s, line: i + 1, col: col})
+			}
+			col += len(s) + 1
+		}
 	}
-	pat := regexp.MustCompile(`[,\n]+`)
-	steps := pat.Split(strings.TrimSpace(string(b)), -1)
-	fmt.Println(part1(steps))
-	fmt.Println(part2(steps))
+	return steps
+}
+
+func init() {
+	runner.Register(runner.Day{
+		Number: 15,
+		Part1:  func(ctx context.Context, lines []string) (int, error) { return part1(split(lines)), nil },
+		Part2:  func(ctx context.Context, lines []string) (int, error) { return part2(split(lines)) },
+	})
 }
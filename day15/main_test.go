@@ -0,0 +1,40 @@
+package day15
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kentquirk/aoc2023/parsing"
+)
+
+func Test_part2Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		lines     []string
+		line, col int
+		want      string
+	}{
+		{"bad step", []string{"rn=1,cm-,qp=x"}, 1, 10, `"qp=x" doesn't match`},
+		{"no focal length", []string{"rn=1,cm="}, 1, 8, "= needs a focal length"},
+		{"focal length 0", []string{"rn=1,cm=0"}, 1, 9, "focal length must be 1-9, not 0"},
+		{"focal length 10", []string{"rn=1,cm=10"}, 1, 9, "focal length must be 1-9, not 10"},
+		{"focal length to remove", []string{"rn=1,cm-3"}, 1, 9, "only = takes a focal length"},
+		{"second line", []string{"rn=1,cm-,", "qp=3,,ab=x"}, 2, 7, `"ab=x" doesn't match`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := part2(split(tt.lines))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("part2() error = %v, want one saying %q", err, tt.want)
+			}
+			var pe *parsing.Error
+			if !errors.As(err, &pe) {
+				t.Fatalf("part2() = %v, want a *parsing.Error", err)
+			}
+			if pe.Line != tt.line || pe.Col != tt.col {
+				t.Errorf("part2() error at %d:%d, want %d:%d (%v)", pe.Line, pe.Col, tt.line, tt.col, err)
+			}
+		})
+	}
+}
@@ -1,39 +1,25 @@
-package main
+package day16
 
 import (
+	"context"
 	"fmt"
-	"io"
-	"log"
-	"os"
-	"strings"
+	"log/slog"
+
+	"github.com/kentquirk/aoc2023/collections"
+	"github.com/kentquirk/aoc2023/grid"
+	"github.com/kentquirk/aoc2023/parsing"
+	"github.com/kentquirk/aoc2023/progress"
+	"github.com/kentquirk/aoc2023/runner"
+	"github.com/kentquirk/aoc2023/trace"
 )
 
-type coord struct {
-	r int
-	c int
-}
-
-type direction struct {
-	dr int
-	dc int
-}
+// direction is the way a beam is going, as an offset.
+type direction = grid.Point
 
 type tilekind byte
 
-type tile struct {
-	kind    tilekind
-	visited map[direction]struct{}
-}
-
-func newTile(kind tilekind) *tile {
-	return &tile{
-		kind:    kind,
-		visited: make(map[direction]struct{}),
-	}
-}
-
-func (t tile) String() string {
-	return string(t.kind)
+func (k tilekind) String() string {
+	return string(k)
 }
 
 const (
@@ -44,149 +30,148 @@ const (
 	vsplit  tilekind = '|'
 )
 
-type grid struct {
-	width  int
-	height int
-	coords map[coord]*tile
+type contraption struct {
+	*grid.Grid[tilekind]
 }
 
-func load(lines []string) grid {
-	g := grid{
-		width:  len(lines[0]),
-		height: len(lines),
-		coords: make(map[coord]*tile),
+// energized records the directions beams have crossed each tile in, for one
+// starting beam.
+type energized map[grid.Point]collections.Set[direction]
+
+func load(lines []string) (contraption, error) {
+	g, err := grid.Parse(lines, func(r rune) tilekind { return tilekind(r) })
+	if err != nil {
+		return contraption{}, err
 	}
-	for r, line := range lines {
-		for c, char := range line {
-			if char == '.' {
-				continue
-			}
-			g.coords[coord{r: r, c: c}] = newTile(tilekind(char))
+	for _, p := range g.Points() {
+		switch k := g.At(p); k {
+		case empty, fmirror, bmirror, hsplit, vsplit:
+		default:
+			return contraption{}, parsing.At(p.Row+1, parsing.Errorf(p.Col+1, "%q is not a mirror, splitter or space", k))
 		}
 	}
-	return g
+	return contraption{g}, nil
 }
 
-func (g grid) follow(beam coord, dir direction) {
-	// fmt.Println(beam, dir, g.coords[beam])
-	beam.r += dir.dr
-	beam.c += dir.dc
-	if beam.r < 0 || beam.c < 0 {
-		return
+var tbeam = trace.New("day16", "beam")
+
+func (g contraption) follow(e energized, beam grid.Point, dir direction) {
+	// this runs for every tile a beam crosses, so don't box the args
+	// unless they'll be used
+	if tbeam.Enabled() {
+		tbeam.Log("follow", "beam", beam, "dir", dir, "tile", g.At(beam))
 	}
-	if beam.r >= g.height || beam.c >= g.width {
+	beam = beam.Add(dir)
+	k, ok := g.Get(beam)
+	if !ok {
 		return
 	}
-	if _, ok := g.coords[beam]; !ok {
-		g.coords[beam] = newTile(empty)
+	dirs, ok := e[beam]
+	if !ok {
+		dirs = make(collections.Set[direction])
+		e[beam] = dirs
 	}
-	if _, ok := g.coords[beam].visited[dir]; ok {
+	if dirs.Contains(dir) {
 		return
 	}
-	g.coords[beam].visited[dir] = struct{}{}
-	switch g.coords[beam].kind {
+	dirs.Add(dir)
+	switch k {
 	case empty:
-		g.follow(beam, dir)
+		g.follow(e, beam, dir)
 	case fmirror:
-		g.follow(beam, direction{dr: -dir.dc, dc: -dir.dr})
+		g.follow(e, beam, direction{Row: -dir.Col, Col: -dir.Row})
 	case bmirror:
-		g.follow(beam, direction{dr: dir.dc, dc: dir.dr})
+		g.follow(e, beam, direction{Row: dir.Col, Col: dir.Row})
 	case hsplit:
-		if dir.dc == 0 {
-			g.follow(beam, direction{dc: 1})
-			g.follow(beam, direction{dc: -1})
+		if dir.Col == 0 {
+			g.follow(e, beam, grid.Right)
+			g.follow(e, beam, grid.Left)
 		} else {
-			g.follow(beam, dir)
+			g.follow(e, beam, dir)
 		}
 	case vsplit:
-		if dir.dr == 0 {
-			g.follow(beam, direction{dr: 1})
-			g.follow(beam, direction{dr: -1})
+		if dir.Row == 0 {
+			g.follow(e, beam, grid.Down)
+			g.follow(e, beam, grid.Up)
 		} else {
-			g.follow(beam, dir)
+			g.follow(e, beam, dir)
 		}
 	}
 }
 
-func (g grid) count() int {
-	count := 0
-	for _, tile := range g.coords {
-		if len(tile.visited) > 0 {
-			count++
-		}
-	}
-	return count
-}
-
-func (g grid) Print(visited bool) {
-	for r := 0; r < g.height; r++ {
-		for c := 0; c < g.width; c++ {
-			if tile, ok := g.coords[coord{r: r, c: c}]; ok {
-				if visited {
-					if len(tile.visited) == 0 {
-						fmt.Print(".")
-					} else {
-						fmt.Print("#")
-					}
-				} else {
-					fmt.Print(tile.kind)
-				}
-			} else {
+// Print prints the contraption, or if e isn't nil, which tiles it
+// energizes.
+func (g contraption) Print(e energized) {
+	for r := 0; r < g.Height(); r++ {
+		for c, k := range g.Row(r) {
+			_, on := e[grid.Point{Row: r, Col: c}]
+			switch {
+			case e == nil:
+				fmt.Print(k)
+			case !on:
 				fmt.Print(".")
+			default:
+				fmt.Print("#")
 			}
 		}
 		fmt.Println()
 	}
 }
 
-func checkFrom(lines []string, co coord, dir direction) int {
-	g := load(lines)
-	g.follow(co, dir)
-	return g.count()
+// checkFrom returns the number of tiles energized by a beam entering from
+// co going in direction dir.
+func checkFrom(g contraption, co grid.Point, dir direction) int {
+	e := make(energized)
+	g.follow(e, co, dir)
+	return len(e)
 }
 
-func part1(lines []string) int {
-	return checkFrom(lines, coord{r: 0, c: -1}, direction{dc: 1})
+func part1(ctx context.Context, lines []string) (int, error) {
+	g, err := load(lines)
+	if err != nil {
+		return 0, err
+	}
+	return checkFrom(g, grid.Point{Row: 0, Col: -1}, grid.Right), nil
 }
 
-func part2(lines []string) int {
-	g := load(lines)
-	scores := make(map[coord]int)
-	for r := 0; r < g.height; r++ {
-		scores[coord{r: r, c: -1}] = checkFrom(lines, coord{r: r, c: -1}, direction{dc: 1})
-		scores[coord{r: r, c: g.width}] = checkFrom(lines, coord{r: r, c: g.width}, direction{dc: -1})
+func part2(ctx context.Context, lines []string) (int, error) {
+	g, err := load(lines)
+	if err != nil {
+		return 0, err
+	}
+	scores := make(map[grid.Point]int)
+	starts := progress.Start(ctx, "starts", 2*(g.Height()+g.Width()))
+	defer starts.Done()
+	try := func(start grid.Point, dir direction) {
+		scores[start] = checkFrom(g, start, dir)
+		starts.Add(1)
+	}
+	for r := 0; r < g.Height(); r++ {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		try(grid.Point{Row: r, Col: -1}, grid.Right)
+		try(grid.Point{Row: r, Col: g.Width()}, grid.Left)
 	}
-	for c := 0; c < g.width; c++ {
-		scores[coord{r: -1, c: c}] = checkFrom(lines, coord{r: -1, c: c}, direction{dr: 1})
-		scores[coord{r: g.height, c: c}] = checkFrom(lines, coord{r: g.height, c: c}, direction{dr: -1})
+	for c := 0; c < g.Width(); c++ {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		try(grid.Point{Row: -1, Col: c}, grid.Down)
+		try(grid.Point{Row: g.Height(), Col: c}, grid.Up)
 	}
 	max := 0
-	best := coord{}
+	best := grid.Point{}
 	for c, score := range scores {
 		if score > max {
 			max = score
 			best = c
 		}
 	}
-	fmt.Println(best, max)
-	return max
+	slog.Debug("best start", "coord", best, "energized", max)
+	return max, nil
 }
 
-func main() {
-	args := os.Args[1:]
-	name := "sample"
-	if len(args) > 0 {
-		name = args[0]
-	}
-	f, err := os.Open(fmt.Sprintf("./data/%s.txt", name))
-	if err != nil {
-		log.Fatal(err)
-	}
-	b, err := io.ReadAll(f)
-	if err != nil {
-		log.Fatal(err)
-	}
-	lines := strings.Split(string(b), "\n")
-	fmt.Println(part1(lines))
-	fmt.Println(part2(lines))
+func init() {
+	runner.Register(runner.Day{Number: 16, Part1: part1, Part2: part2})
 }
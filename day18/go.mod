@@ -1,3 +0,0 @@
-module github.com/kentquirk/aoc2023/day18
-
-go 1.20
@@ -1,34 +1,33 @@
-package main
+package day18
 
 import (
-	"fmt"
-	"io"
-	"log"
-	"os"
+	"context"
+	"errors"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+
+	"github.com/kentquirk/aoc2023/interval"
+	"github.com/kentquirk/aoc2023/parsing"
+	"github.com/kentquirk/aoc2023/progress"
+	"github.com/kentquirk/aoc2023/runner"
+	"github.com/kentquirk/aoc2023/trace"
 )
 
 type color string
 
 type run struct {
-	first int
-	last  int
+	interval.Interval
 	color color
 	fill  bool
 }
 
 type contigRun struct {
-	first int
-	last  int
+	interval.Interval
 	cross bool
 }
 
-func (r contigRun) width() int {
-	return r.last - r.first + 1
-}
-
 type row struct {
 	runs []*run
 }
@@ -42,16 +41,16 @@ func newRow() *row {
 func (r *row) add(ru *run) {
 	r.runs = append(r.runs, ru)
 	sort.Slice(r.runs, func(i, j int) bool {
-		return r.runs[i].first < r.runs[j].first
+		return r.runs[i].Lo < r.runs[j].Lo
 	})
 }
 
 func (r *row) colorAt(c int) color {
 	for _, ru := range r.runs {
-		if ru.first <= c && c <= ru.last {
+		if ru.Contains(c) {
 			return ru.color
 		}
-		if ru.first > c {
+		if ru.Lo > c {
 			return ""
 		}
 	}
@@ -60,10 +59,10 @@ func (r *row) colorAt(c int) color {
 
 func (r *row) isTrench(c int) bool {
 	for _, ru := range r.runs {
-		if ru.first <= c && c <= ru.last {
+		if ru.Contains(c) {
 			return !ru.fill
 		}
-		if ru.first > c {
+		if ru.Lo > c {
 			return false
 		}
 	}
@@ -72,10 +71,10 @@ func (r *row) isTrench(c int) bool {
 
 func (r *row) isFilled(c int) bool {
 	for _, ru := range r.runs {
-		if ru.first <= c && c <= ru.last {
+		if ru.Contains(c) {
 			return ru.color != ""
 		}
-		if ru.first > c {
+		if ru.Lo > c {
 			return false
 		}
 	}
@@ -86,30 +85,31 @@ func (r *row) isFilled(c int) bool {
 // so "#..#####..#" returns (0,0), (3, 8), (11,11)
 // each run is also tagged with whether it's a cross or not.
 func (l *lagoon) contiguous(rix int) []contigRun {
+	runs := l.rows[rix].runs
 	contigs := []contigRun{}
-	cur := contigRun{first: l.rows[rix].runs[0].first}
-	for i := 1; i < len(l.rows[rix].runs); {
-		if l.rows[rix].runs[i-1].last+1 == l.rows[rix].runs[i].first {
-			i++
-		} else {
-			cur.last = l.rows[rix].runs[i-1].last
+	cur := contigRun{Interval: runs[0].Interval}
+	for i := 1; i <= len(runs); i++ {
+		if i < len(runs) && cur.Hi == runs[i].Lo {
+			cur.Hi = runs[i].Hi
+			continue
+		}
+		if i < len(runs) {
 			// before we add it to contigs, we need to check the previous row
 			// at the edges of the run to see if if this run is a cross or a cap.
 			// it's a cross if it's only 1 wide
-			if cur.first == cur.last {
+			if cur.Len() == 1 {
 				cur.cross = true
 			}
 			// it's a cross if the previous row has one edge up and one edge down
-			if rix > 0 && (l.rows[rix-1].isTrench(cur.first) != l.rows[rix-1].isTrench(cur.last)) {
+			if rix > 0 && (l.rows[rix-1].isTrench(cur.Lo) != l.rows[rix-1].isTrench(cur.Last())) {
 				cur.cross = true
 			}
-			contigs = append(contigs, cur)
-			cur = contigRun{first: l.rows[rix].runs[i].first}
-			i++
+		}
+		contigs = append(contigs, cur)
+		if i < len(runs) {
+			cur = contigRun{Interval: runs[i].Interval}
 		}
 	}
-	cur.last = l.rows[rix].runs[len(l.rows[rix].runs)-1].last
-	contigs = append(contigs, cur)
 	return contigs
 }
 
@@ -134,8 +134,8 @@ func (l *lagoon) left() int {
 			continue
 		}
 
-		if left > row.runs[0].first {
-			left = row.runs[0].first
+		if left > row.runs[0].Lo {
+			left = row.runs[0].Lo
 		}
 	}
 	return left
@@ -148,20 +148,26 @@ func (l *lagoon) right() int {
 			continue
 		}
 
-		if right < row.runs[len(row.runs)-1].last {
-			right = row.runs[len(row.runs)-1].last
+		if right < row.runs[len(row.runs)-1].Last() {
+			right = row.runs[len(row.runs)-1].Last()
 		}
 	}
 	return right
 }
 
+var (
+	tcount  = trace.New("day18", "count")
+	tlagoon = trace.New("day18", "lagoon")
+	tdig    = trace.New("day18", "dig")
+)
+
 func (l *lagoon) count() int {
 	c := 0
 	for _, row := range l.rows {
 		for _, ru := range row.runs {
-			c += ru.last - ru.first + 1
+			c += ru.Len()
 		}
-		// fmt.Println(c)
+		tcount.Log("count", "total", c)
 	}
 	return c
 }
@@ -173,10 +179,9 @@ func (l *lagoon) fill() {
 		for i := 1; i < len(cont); i++ {
 			if inside {
 				row.add(&run{
-					first: cont[i-1].last + 1,
-					last:  cont[i].first - 1,
-					color: "#ffffff",
-					fill:  true,
+					Interval: interval.Interval{Lo: cont[i-1].Hi, Hi: cont[i].Lo},
+					color:    "#ffffff",
+					fill:     true,
 				})
 			}
 			if cont[i].cross {
@@ -186,25 +191,34 @@ func (l *lagoon) fill() {
 	}
 }
 
-func (l *lagoon) fillCount() int {
+func (l *lagoon) fillCount(ctx context.Context) (int, error) {
 	count := 0
+	rows := progress.Start(ctx, "rows", len(l.rows))
+	defer rows.Done()
 	for ix := range l.rows {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		if len(l.rows[ix].runs) == 0 {
+			continue
+		}
 		cont := l.contiguous(ix)
 		inside := cont[0].cross
-		rowcount := cont[0].width()
+		rowcount := cont[0].Len()
 		for i := 1; i < len(cont); i++ {
-			rowcount += cont[i].width()
+			rowcount += cont[i].Len()
 			if inside {
-				rowcount += cont[i].first - cont[i-1].last - 1
+				rowcount += cont[i].Lo - cont[i-1].Hi
 			}
 			if cont[i].cross {
 				inside = !inside
 			}
 		}
-		// fmt.Println(ix, rowcount)
+		tcount.Log("row", "ix", ix, "count", rowcount)
 		count += rowcount
+		rows.Set(ix + 1)
 	}
-	return count
+	return count, nil
 }
 
 func (l *lagoon) String() string {
@@ -228,9 +242,13 @@ type Instruction struct {
 	operation string
 	argument  int
 	color     color
+	// line is where the instruction came from in the input
+	line int
 }
 
-func (i *Instruction) part2fix() {
+// part2fix reads the real instruction out of the color. parseInstruction
+// has already checked that it's six hex digits.
+func (i *Instruction) part2fix() error {
 	sarg := string(i.color[1:6])
 	sop := i.color[6:]
 	arg, _ := strconv.ParseInt(sarg, 16, 64)
@@ -245,30 +263,50 @@ func (i *Instruction) part2fix() {
 	case "3":
 		i.operation = "U"
 	default:
-		panic(sop)
+		return parsing.Errorf(len(i.color), "%q is not a direction", sop)
 	}
+	return nil
 }
 
-func parseInstruction(line string) Instruction {
-	parts := strings.Split(line, " ")
-	i, _ := strconv.Atoi(parts[1])
-	return Instruction{
-		operation: parts[0],
-		argument:  i,
-		color:     color(parts[2][1 : len(parts[2])-1]),
+var inspat = regexp.MustCompile(`^(?P<operation>[RDLU]) (?P<argument>\d+) \((?P<color>#[0-9a-f]{6})\)$`)
+
+func parseInstruction(line string) (Instruction, error) {
+	var ins struct {
+		Operation string
+		Argument  int
+		Color     string
+	}
+	if err := parsing.Bind(inspat, line, &ins); err != nil {
+		return Instruction{}, err
 	}
+	return Instruction{
+		operation: ins.Operation,
+		argument:  ins.Argument,
+		color:     color(ins.Color),
+	}, nil
 }
 
-func parseInstructions(lines []string, fix bool) []Instruction {
+func parseInstructions(lines []string, fix bool) ([]Instruction, error) {
 	var instructions []Instruction
-	for _, line := range lines {
-		ins := parseInstruction(line)
-		if fix {
-			ins.part2fix()
+	for n, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
 		}
+		ins, err := parseInstruction(line)
+		if err == nil && fix {
+			// the color starts just after the "("
+			err = parsing.Offset(strings.Index(line, "(")+1, ins.part2fix())
+		}
+		if err != nil {
+			return nil, parsing.At(n+1, err)
+		}
+		ins.line = n + 1
 		instructions = append(instructions, ins)
 	}
-	return instructions
+	if len(instructions) == 0 {
+		return nil, errors.New("no dig instructions")
+	}
+	return instructions, nil
 }
 
 // expands rows and returns the new row index
@@ -291,88 +329,81 @@ func (l *lagoon) maybeExpand(r, dr int) int {
 	return r
 }
 
-func (l *lagoon) dig(r, c int, instruction Instruction) (int, int) {
+func (l *lagoon) dig(r, c int, instruction Instruction) (int, int, error) {
 	switch instruction.operation {
 	case "R":
 		l.rows[r].add(&run{
-			first: c + 1,
-			last:  c + instruction.argument,
-			color: instruction.color,
+			Interval: interval.Span(c+1, instruction.argument),
+			color:    instruction.color,
 		})
-		return r, c + instruction.argument
+		return r, c + instruction.argument, nil
 	case "L":
 		l.rows[r].add(&run{
-			first: c - instruction.argument,
-			last:  c - 1,
-			color: instruction.color,
+			Interval: interval.Span(c-instruction.argument, instruction.argument),
+			color:    instruction.color,
 		})
-		return r, c - instruction.argument
+		return r, c - instruction.argument, nil
 	case "U":
 		r = l.maybeExpand(r, -instruction.argument)
 		for i := 1; i <= instruction.argument; i++ {
 			l.rows[r-i].add(&run{
-				first: c,
-				last:  c,
-				color: instruction.color,
+				Interval: interval.Span(c, 1),
+				color:    instruction.color,
 			})
 		}
-		return r - instruction.argument, c
+		return r - instruction.argument, c, nil
 	case "D":
 		r = l.maybeExpand(r, instruction.argument)
 		for i := 1; i <= instruction.argument; i++ {
 			l.rows[r+i].add(&run{
-				first: c,
-				last:  c,
-				color: instruction.color,
+				Interval: interval.Span(c, 1),
+				color:    instruction.color,
 			})
 		}
-		return r + instruction.argument, c
+		return r + instruction.argument, c, nil
 	}
-	panic("unknown operation")
+	return r, c, parsing.At(instruction.line, parsing.Errorf(1, "unknown operation %q", instruction.operation))
 }
 
-func part1(lines []string) int {
+func part1(ctx context.Context, lines []string) (int, error) {
 	lagoon := newLagoon()
-	instructions := parseInstructions(lines, false)
+	instructions, err := parseInstructions(lines, false)
+	if err != nil {
+		return 0, err
+	}
 	r, c := 0, 0
 	for _, instruction := range instructions {
-		r, c = lagoon.dig(r, c, instruction)
+		if r, c, err = lagoon.dig(r, c, instruction); err != nil {
+			return 0, err
+		}
 	}
-	// fmt.Println(lagoon)
-	return lagoon.fillCount()
-	// lagoon.fill()
-	// fmt.Println("-------")
-	// fmt.Println(lagoon)
-	// return lagoon.count()
+	tlagoon.Log("dug", "lagoon", lagoon)
+	return lagoon.fillCount(ctx)
 }
 
-func part2(lines []string) int {
+func part2(ctx context.Context, lines []string) (int, error) {
 	lagoon := newLagoon()
-	instructions := parseInstructions(lines, true)
+	instructions, err := parseInstructions(lines, true)
+	if err != nil {
+		return 0, err
+	}
 	r, c := 0, 0
+	dug := progress.Start(ctx, "instructions", len(instructions))
+	defer dug.Done()
 	for _, instruction := range instructions {
-		// fmt.Println("digging for ", instruction)
-		r, c = lagoon.dig(r, c, instruction)
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		tdig.Log("digging", "instruction", instruction)
+		if r, c, err = lagoon.dig(r, c, instruction); err != nil {
+			return 0, err
+		}
+		dug.Add(1)
 	}
-	// fmt.Println("calculating fill")
-	return lagoon.fillCount()
+	tdig.Log("calculating fill")
+	return lagoon.fillCount(ctx)
 }
 
-func main() {
-	args := os.Args[1:]
-	name := "sample"
-	if len(args) > 0 {
-		name = args[0]
-	}
-	f, err := os.Open(fmt.Sprintf("./data/%s.txt", name))
-	if err != nil {
-		log.Fatal(err)
-	}
-	b, err := io.ReadAll(f)
-	if err != nil {
-		log.Fatal(err)
-	}
-	lines := strings.Split(string(b), "\n")
-	fmt.Println(part1(lines))
-	fmt.Println(part2(lines))
+func init() {
+	runner.Register(runner.Day{Number: 18, Part1: part1, Part2: part2})
 }
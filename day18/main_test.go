@@ -0,0 +1,41 @@
+package day18
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kentquirk/aoc2023/parsing"
+)
+
+func Test_parseInstructionsErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		lines     []string
+		fix       bool
+		line, col int
+		want      string
+	}{
+		{"empty", nil, false, 0, 0, "no dig instructions"},
+		{"blank", []string{"", "  "}, false, 0, 0, "no dig instructions"},
+		{"bad count", []string{"R 6 (#70c710)", "D x (#0dc571)"}, false, 2, 1, `"D x (#0dc571)" doesn't match`},
+		{"bad color", []string{"R 6 (#70c710)", "D 5 (#0dc57g)"}, false, 2, 1, `"D 5 (#0dc57g)" doesn't match`},
+		{"bad direction", []string{"R 6 (#70c710)", "D 5 (#0dc574)"}, true, 2, 12, `"4" is not a direction`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseInstructions(tt.lines, tt.fix)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("parseInstructions() error = %v, want one saying %q", err, tt.want)
+			}
+			line, col := 0, 0
+			var pe *parsing.Error
+			if errors.As(err, &pe) {
+				line, col = pe.Line, pe.Col
+			}
+			if line != tt.line || col != tt.col {
+				t.Errorf("parseInstructions() error at %d:%d, want %d:%d (%v)", line, col, tt.line, tt.col, err)
+			}
+		})
+	}
+}
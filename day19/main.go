@@ -1,26 +1,41 @@
-package main
+package day19
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"io"
-	"log"
-	"os"
 	"regexp"
-	"strconv"
 	"strings"
+
+	"github.com/kentquirk/aoc2023/interval"
+	"github.com/kentquirk/aoc2023/parsing"
+	"github.com/kentquirk/aoc2023/runner"
 )
 
 type part struct {
 	ratings map[string]int
 }
 
-func newPart(s string) *part {
-	pat := regexp.MustCompile(`(\w+)=([0-9]+)`)
+func newPart(s string) (*part, error) {
+	if !strings.HasPrefix(s, "{") || !strings.HasSuffix(s, "}") {
+		return nil, parsing.Errorf(1, "%q is not a part", s)
+	}
 	p := &part{ratings: make(map[string]int)}
-	for _, m := range pat.FindAllStringSubmatch(s, -1) {
-		p.ratings[m[1]], _ = strconv.Atoi(m[2])
+	col := 1
+	for _, kv := range strings.Split(s[1:len(s)-1], ",") {
+		k, v, err := parsing.KeyValue(kv, "=")
+		if err != nil {
+			return nil, parsing.Offset(col, err)
+		}
+		if !strings.Contains("xmas", k) || len(k) != 1 {
+			return nil, parsing.Errorf(col+1, "%q is not a category", k)
+		}
+		if p.ratings[k], err = parsing.Int(v); err != nil {
+			return nil, parsing.Offset(col+len(k)+1, err)
+		}
+		col += len(kv) + 1
 	}
-	return p
+	return p, nil
 }
 
 func (p *part) rating() int {
@@ -33,30 +48,43 @@ func (p *part) rating() int {
 
 type parts []*part
 
+// rule is one step of a workflow. key, op and value describe its condition
+// ("a", '<', 3 for "a<3"; op is 0 for a rule that always applies) and dest
+// where it sends the part, which is what part2 needs to work on whole ranges
+// of parts at once.
 type rule struct {
 	condition func(p *part) bool
 	action    func(p *part) (string, bool)
+	key       string
+	op        byte
+	value     int
+	dest      string
 }
 
-func newRule(s string, accept, reject func()) rule {
-	// "a<3:b" or "b"
-	// group 1: key
-	// group 2: comparator
-	// group 3: value
-	// group 4: destination
-	pat := regexp.MustCompile(`(?:(\w+)([<>]?)([0-9]+):)?(\w+)`)
-	m := pat.FindStringSubmatch(s)
+// rulepat is "a<3:b" or just "b".
+var rulepat = regexp.MustCompile(`^(?:(?P<key>[xmas])(?P<op>[<>])(?P<value>[0-9]+):)?(?P<dest>\w+)$`)
+
+func newRule(s string, accept, reject func()) (rule, error) {
+	var m struct {
+		Key, Op, Dest string
+		Value         int
+	}
+	if err := parsing.Bind(rulepat, s, &m); err != nil {
+		return rule{}, err
+	}
 	var cond func(p *part) bool
 	var action func(p *part) (string, bool)
+	r := rule{key: m.Key, dest: m.Dest}
 
-	if m[1] == "" {
+	if m.Key == "" {
 		cond = func(p *part) bool {
 			return true
 		}
 	} else {
-		key := m[1]
-		cv, _ := strconv.Atoi(m[3])
-		switch m[2] {
+		key := m.Key
+		cv := m.Value
+		r.op, r.value = m.Op[0], cv
+		switch m.Op {
 		case "<":
 			cond = func(p *part) bool {
 				return p.ratings[key] < cv
@@ -68,7 +96,7 @@ func newRule(s string, accept, reject func()) rule {
 		}
 	}
 
-	switch m[4] {
+	switch m.Dest {
 	case "A":
 		action = func(p *part) (string, bool) {
 			accept()
@@ -81,71 +109,146 @@ func newRule(s string, accept, reject func()) rule {
 		}
 	default:
 		action = func(p *part) (string, bool) {
-			return m[4], false
+			return m.Dest, false
 		}
 	}
-	return rule{condition: cond, action: action}
+	r.condition, r.action = cond, action
+	return r, nil
 }
 
 type workflow struct {
 	name  string
 	rules []rule
+	// line is where the workflow came from in the input
+	line int
 }
 
-func newWorkflow(s string, accept, reject func()) *workflow {
-	pat := regexp.MustCompile(`(\w+){(.*)}`)
-	m := pat.FindStringSubmatch(s)
-	w := &workflow{name: m[1]}
-	for _, l := range strings.Split(m[2], ",") {
-		w.rules = append(w.rules, newRule(l, accept, reject))
+var workflowpat = regexp.MustCompile(`^(?P<name>\w+)\{(?P<rules>.*)\}$`)
+
+func newWorkflow(s string, accept, reject func()) (*workflow, error) {
+	var m struct{ Name, Rules string }
+	if err := parsing.Bind(workflowpat, s, &m); err != nil {
+		return nil, err
+	}
+	w := &workflow{name: m.Name}
+	col := len(m.Name) + 1
+	for _, l := range strings.Split(m.Rules, ",") {
+		r, err := newRule(l, accept, reject)
+		if err != nil {
+			return nil, parsing.Offset(col, err)
+		}
+		w.rules = append(w.rules, r)
+		col += len(l) + 1
+	}
+	if w.rules[len(w.rules)-1].op != 0 {
+		return nil, parsing.Errorf(col-1, "workflow %s has no rule for parts that match nothing else", w.name)
 	}
-	return w
+	return w, nil
 }
 
-func (w *workflow) run(p *part) (string, bool) {
+func (w *workflow) run(p *part) (string, bool, error) {
 	for _, r := range w.rules {
 		if r.condition(p) {
-			return r.action(p)
+			next, done := r.action(p)
+			return next, done, nil
 		}
 	}
-	// no rule matched - can't happen
-	panic("no rule matched")
+	// newWorkflow makes sure the last rule always matches, so this is a bug
+	return "", false, parsing.At(w.line, fmt.Errorf("no rule in workflow %s matched %v", w.name, p.ratings))
 }
 
 type workshop map[string]*workflow
 
-func (w workshop) run(p *part) bool {
+func (w workshop) run(p *part) (bool, error) {
 	currentWorkflow := w["in"]
 	for {
-		next, done := currentWorkflow.run(p)
-		if done {
-			return next == "A"
+		next, done, err := currentWorkflow.run(p)
+		if err != nil || done {
+			return next == "A", err
 		}
 		currentWorkflow = w[next]
 	}
 }
 
-func parse(lines []string, afunc, rfunc func()) (workshop, parts) {
+// loop returns the name of a workflow that can send a part back round to
+// itself, or "" if there are none. Parts would go round them forever.
+func (w workshop) loop() string {
+	const (
+		unseen = iota
+		visiting
+		done
+	)
+	state := make(map[string]int)
+	var visit func(name string) string
+	visit = func(name string) string {
+		wf, ok := w[name]
+		if !ok {
+			return "" // A or R
+		}
+		switch state[name] {
+		case visiting:
+			return name
+		case done:
+			return ""
+		}
+		state[name] = visiting
+		for _, r := range wf.rules {
+			if l := visit(r.dest); l != "" {
+				return l
+			}
+		}
+		state[name] = done
+		return ""
+	}
+	for name := range w {
+		if l := visit(name); l != "" {
+			return l
+		}
+	}
+	return ""
+}
+
+func parse(lines []string, afunc, rfunc func()) (workshop, parts, error) {
 	workflows := make(workshop)
 	parts := make([]*part, 0)
-	buildParts := false
-	for _, l := range lines {
-		if l == "" {
-			buildParts = true
-			continue
+	blocks := parsing.Blocks(lines)
+	if len(blocks) != 2 {
+		return nil, nil, fmt.Errorf("want workflows and parts, found %d blocks", len(blocks))
+	}
+	var order []*workflow
+	for i, l := range blocks[0].Lines {
+		w, err := newWorkflow(l, afunc, rfunc)
+		if err != nil {
+			return nil, nil, blocks[0].At(i, err)
 		}
-		if buildParts {
-			p := newPart(l)
-			parts = append(parts, p)
-		} else {
-			w := newWorkflow(l, afunc, rfunc)
-			workflows[w.name] = w
+		w.line = blocks[0].Start + i
+		workflows[w.name] = w
+		order = append(order, w)
+	}
+	for i, l := range blocks[1].Lines {
+		p, err := newPart(l)
+		if err != nil {
+			return nil, nil, blocks[1].At(i, err)
 		}
+		parts = append(parts, p)
+	}
+	if _, ok := workflows["in"]; !ok {
+		return nil, nil, errors.New("there is no workflow named in")
 	}
-	return workflows, parts
+	for i, w := range order {
+		for _, r := range w.rules {
+			if _, ok := workflows[r.dest]; !ok && r.dest != "A" && r.dest != "R" {
+				return nil, nil, blocks[0].At(i, fmt.Errorf("there is no workflow named %s", r.dest))
+			}
+		}
+	}
+	if l := workflows.loop(); l != "" {
+		return nil, nil, parsing.At(workflows[l].line, fmt.Errorf("parts can go round in a loop through workflow %s", l))
+	}
+	return workflows, parts, nil
 }
 
-func part1(lines []string) int {
+func part1(ctx context.Context, lines []string) (int, error) {
 	accepted := 0
 	rejected := 0
 	afunc := func() {
@@ -154,35 +257,87 @@ func part1(lines []string) int {
 	rfunc := func() {
 		rejected++
 	}
-	workflows, parts := parse(lines, afunc, rfunc)
+	workflows, parts, err := parse(lines, afunc, rfunc)
+	if err != nil {
+		return 0, err
+	}
 	totalRating := 0
 	for _, p := range parts {
-		if workflows.run(p) {
+		ok, err := workflows.run(p)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
 			totalRating += p.rating()
 		}
 	}
 
-	return totalRating
+	return totalRating, nil
 }
 
-func part2(lines []string) int {
-	return 0
+// ratings holds a range of values for each category.
+type ratings map[string]interval.Interval
+
+func (r ratings) with(key string, i interval.Interval) ratings {
+	n := make(ratings, len(r))
+	for k, v := range r {
+		n[k] = v
+	}
+	n[key] = i
+	return n
 }
 
-func main() {
-	args := os.Args[1:]
-	name := "sample"
-	if len(args) > 0 {
-		name = args[0]
+func (r ratings) combinations() int {
+	n := 1
+	for _, i := range r {
+		n *= i.Len()
 	}
-	f, err := os.Open(fmt.Sprintf("./data/%s.txt", name))
-	if err != nil {
-		log.Fatal(err)
+	return n
+}
+
+// accepted counts the combinations of ratings in r that end up accepted,
+// starting at the named workflow. Each rule splits the ranges in two: the
+// part that matches goes where the rule says, and the rest carries on to the
+// next rule.
+func (w workshop) accepted(name string, r ratings) int {
+	switch name {
+	case "A":
+		return r.combinations()
+	case "R":
+		return 0
 	}
-	b, err := io.ReadAll(f)
+	total := 0
+	for _, rule := range w[name].rules {
+		if rule.op == 0 {
+			return total + w.accepted(rule.dest, r)
+		}
+		var match, rest interval.Interval
+		switch rule.op {
+		case '<':
+			match, rest = r[rule.key].SplitAt(rule.value)
+		case '>':
+			rest, match = r[rule.key].SplitAt(rule.value + 1)
+		}
+		if !match.Empty() {
+			total += w.accepted(rule.dest, r.with(rule.key, match))
+		}
+		if rest.Empty() {
+			return total
+		}
+		r = r.with(rule.key, rest)
+	}
+	return total
+}
+
+func part2(ctx context.Context, lines []string) (int, error) {
+	workflows, _, err := parse(lines, func() {}, func() {})
 	if err != nil {
-		log.Fatal(err)
+		return 0, err
 	}
-	lines := strings.Split(string(b), "\n")
-	fmt.Println(part1(lines))
+	all := interval.Closed(1, 4000)
+	return workflows.accepted("in", ratings{"x": all, "m": all, "a": all, "s": all}), nil
+}
+
+func init() {
+	runner.Register(runner.Day{Number: 19, Part1: part1, Part2: part2})
 }
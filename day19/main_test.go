@@ -0,0 +1,46 @@
+package day19
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kentquirk/aoc2023/parsing"
+)
+
+func Test_parseErrors(t *testing.T) {
+	part := []string{"", "{x=1,m=2,a=3,s=4}"}
+	tests := []struct {
+		name      string
+		lines     []string
+		line, col int
+		want      string
+	}{
+		{"no parts", []string{"in{A}"}, 0, 0, "want workflows and parts, found 1 blocks"},
+		{"bad workflow", append([]string{"in{a<2006:A,R"}, part...), 1, 1, `"in{a<2006:A,R" doesn't match`},
+		{"bad rule", append([]string{"in{a<1:A,b>2:R,A}"}, part...), 1, 10, `"b>2:R" doesn't match`},
+		{"no default", append([]string{"in{a<2006:A,x>3:R}"}, part...), 1, 17, "workflow in has no rule for parts that match nothing else"},
+		{"no in", append([]string{"px{a<2006:A,R}"}, part...), 0, 0, "there is no workflow named in"},
+		{"unknown workflow", append([]string{"in{a<2006:A,R}", "px{a<2006:qkq,A}"}, part...), 2, 0, "there is no workflow named qkq"},
+		{"loop", append([]string{"in{a<1:px,A}", "px{a<1:in,R}"}, part...), 1, 0, "parts can go round in a loop through workflow in"},
+		{"bad category", []string{"in{A}", "", "{x=1,m=2,q=3,s=4}"}, 3, 10, `"q" is not a category`},
+		{"bad rating", []string{"in{A}", "", "{x=1,m=two,a=3,s=4}"}, 3, 8, `"two" is not a number`},
+		{"no =", []string{"in{A}", "", "{x=1,m2,a=3,s=4}"}, 3, 6, `no "=" in "m2"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := parse(tt.lines, func() {}, func() {})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("parse() error = %v, want one saying %q", err, tt.want)
+			}
+			line, col := 0, 0
+			var pe *parsing.Error
+			if errors.As(err, &pe) {
+				line, col = pe.Line, pe.Col
+			}
+			if line != tt.line || col != tt.col {
+				t.Errorf("parse() error at %d:%d, want %d:%d (%v)", line, col, tt.line, tt.col, err)
+			}
+		})
+	}
+}
@@ -1,14 +1,23 @@
-package main
+package day20
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"io"
-	"log"
-	"os"
+	"log/slog"
+	"slices"
 	"sort"
 	"strings"
 
 	"github.com/dgryski/go-wyhash"
+
+	"github.com/kentquirk/aoc2023/collections"
+	"github.com/kentquirk/aoc2023/cycle"
+	"github.com/kentquirk/aoc2023/numtheory"
+	"github.com/kentquirk/aoc2023/parsing"
+	"github.com/kentquirk/aoc2023/progress"
+	"github.com/kentquirk/aoc2023/runner"
+	"github.com/kentquirk/aoc2023/trace"
 )
 
 type pulse bool
@@ -147,21 +156,31 @@ type network struct {
 	names   []string
 	modules map[string]module
 	dests   map[string][]string
-	queue   chan *event
+	queue   collections.Deque[*event]
+	// watch holds, for the modules being watched, the first press on
+	// which each sent a high pulse (0 until it has)
+	watch map[string]int
 }
 
-func newNetwork(lines []string) *network {
+func newNetwork(lines []string) (*network, error) {
 	conjunctions := make(map[string]*conjunction)
 	n := &network{
 		names:   make([]string, 0),
 		modules: make(map[string]module),
 		dests:   make(map[string][]string),
-		queue:   make(chan *event, 100),
 	}
-	for _, l := range lines {
-		parts := strings.Split(l, " -> ")
-		src := parts[0]
-		dests := strings.Split(parts[1], ", ")
+	for i, l := range lines {
+		if l == "" {
+			continue
+		}
+		src, rest, err := parsing.KeyValue(l, "->")
+		if err == nil && (src == "" || rest == "") {
+			err = parsing.Errorf(1, "want \"module -> destinations\", found %q", l)
+		}
+		if err != nil {
+			return nil, parsing.At(i+1, err)
+		}
+		dests := strings.Split(rest, ", ")
 		var m module
 		name := ""
 		switch src[0] {
@@ -176,6 +195,12 @@ func newNetwork(lines []string) *network {
 			name = src
 			m = newBroadcast(name)
 		}
+		if m == nil || name == "" || (src[0] == 'b' && name != "broadcaster") {
+			return nil, parsing.At(i+1, parsing.Errorf(1, "%q is not a module", src))
+		}
+		if _, ok := n.modules[name]; ok {
+			return nil, parsing.At(i+1, fmt.Errorf("module %s is listed twice", name))
+		}
 		n.names = append(n.names, name)
 		n.modules[name] = m
 		n.dests[name] = dests
@@ -187,7 +212,10 @@ func newNetwork(lines []string) *network {
 			}
 		}
 	}
-	return n
+	if _, ok := n.modules["broadcaster"]; !ok {
+		return nil, errors.New("there is no broadcaster")
+	}
+	return n, nil
 }
 
 func (n *network) String() string {
@@ -207,33 +235,32 @@ func (n *network) Hash() uint64 {
 	return h
 }
 
-var lastPulse = make(map[string]int)
+var (
+	tpulse   = trace.New("day20", "pulse")
+	tnetwork = trace.New("day20", "network")
+)
 
 func (n *network) processQueue(buttonPresses int) (int, int, bool) {
 	counts := make(map[pulse]int)
-	for len(n.queue) > 0 {
-		cur := <-n.queue
-		// fmt.Println(cur)
+	for n.queue.Len() > 0 {
+		cur := n.queue.PopFront()
+		tpulse.Log("pulse", "event", cur)
 		counts[cur.pul]++
-		// xmtoggles := make(map[string]int)
 		if m, ok := n.modules[cur.dest]; ok {
 			if evt := m.Receive(cur); evt != nil {
 				for _, d := range n.dests[evt.src] {
 					switch d {
 					case "rx":
 						if evt.pul == low {
-							fmt.Println("got low to rx!")
+							slog.Debug("got low to rx", "press", buttonPresses)
 							return counts[high], counts[low], true
 						}
-					case "ft", "jz", "ng", "sv":
-						if evt.pul == low {
-							delta := buttonPresses - lastPulse[d]
-							lastPulse[d] = buttonPresses
-							// this is ugly as FUCK
-							fmt.Printf("got %v from %s to %s at %d (%d)\n", evt.pul, evt.src, d, buttonPresses, delta)
-						}
 					}
-					n.queue <- evt.setDest(d)
+					if first, ok := n.watch[evt.src]; ok && first == 0 && evt.pul == high {
+						slog.Debug("got high", "from", evt.src, "to", d, "press", buttonPresses)
+						n.watch[evt.src] = buttonPresses
+					}
+					n.queue.PushBack(evt.setDest(d))
 				}
 			}
 		}
@@ -242,89 +269,113 @@ func (n *network) processQueue(buttonPresses int) (int, int, bool) {
 }
 
 func (n *network) pressButton() {
-	n.queue <- &event{src: "button", pul: low, dest: "broadcaster"}
+	n.queue.PushBack(&event{src: "button", pul: low, dest: "broadcaster"})
 }
 
-type result struct {
-	high     int
-	low      int
-	nexthash uint64
+// part1 presses the button until the network gets back to a state it has
+// been in before, if that happens within the 1000 presses, and works out the
+// rest from there.
+func part1(ctx context.Context, lines []string) (int, error) {
+	const presses = 1000
+	net, err := newNetwork(lines)
+	if err != nil {
+		return 0, err
+	}
+	tnetwork.Log("network", "net", net)
+	var det cycle.Detector[uint64]
+	var highs, lows []int
+	c := cycle.Cycle{}
+	for i := 0; i < presses; i++ {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		if cy, ok := det.Add(net.Hash()); ok {
+			slog.Debug("back where we were", "presses", i, "start", cy.Start, "period", cy.Period)
+			c = cy
+			break
+		}
+		net.pressButton()
+		high, low, _ := net.processQueue(0)
+		highs = append(highs, high)
+		lows = append(lows, low)
+		tnetwork.Log("pressed", "high", high, "low", low, "hash", net.Hash())
+	}
+	highTotal := cycle.Sum(c, highs, presses)
+	lowTotal := cycle.Sum(c, lows, presses)
+	slog.Debug("totals", "presses", presses, "high", highTotal, "low", lowTotal)
+	return highTotal * lowTotal, nil
 }
 
-func part1(lines []string) int {
-	net := newNetwork(lines)
-	// fmt.Println(net)
-	start := net.Hash()
-	cycles := 0
-	highTotal := 0
-	lowTotal := 0
-	h := start
-	results := make(map[uint64]result)
-	for i := 0; i < 1000; i++ {
-		if r, ok := results[h]; ok {
-			highTotal += r.high
-			lowTotal += r.low
-			h = r.nexthash
-		} else {
-			net.pressButton()
-			high, low, _ := net.processQueue(0)
-			highTotal += high
-			lowTotal += low
-			next := net.Hash()
-			results[h] = result{high, low, next}
-			h = next
-			// fmt.Println(high, low, net.Hash())
-		}
-		cycles++
-		if h == start {
-			fmt.Println("cycle ", cycles, highTotal, lowTotal)
+// feeders returns the modules that send to dest.
+func (n *network) feeders(dest string) []string {
+	var srcs []string
+	for _, name := range n.names {
+		if slices.Contains(n.dests[name], dest) {
+			srcs = append(srcs, name)
 		}
 	}
-	fmt.Println(cycles, highTotal, lowTotal)
-	return highTotal * lowTotal
+	return srcs
 }
 
-func part2(lines []string) int {
-	net := newNetwork(lines)
-	// fmt.Println(net)
+// part2 relies on the shape of the real input (see README.md): rx is fed by
+// a single conjunction, whose inputs are each the end of a counter that
+// sends it a high pulse once every so many presses. rx gets its low pulse
+// when they all do so on the same press, which is the LCM of their periods.
+func part2(ctx context.Context, lines []string) (int, error) {
+	net, err := newNetwork(lines)
+	if err != nil {
+		return 0, err
+	}
+	tnetwork.Log("network", "net", net)
+	rxFeeders := net.feeders("rx")
+	if len(rxFeeders) != 1 {
+		return 0, fmt.Errorf("want one conjunction feeding rx, found %d", len(rxFeeders))
+	}
+	if _, ok := net.modules[rxFeeders[0]].(*conjunction); !ok {
+		return 0, fmt.Errorf("want a conjunction feeding rx, found %s", net.modules[rxFeeders[0]])
+	}
+	net.watch = make(map[string]int)
+	for _, name := range net.feeders(rxFeeders[0]) {
+		net.watch[name] = 0
+	}
+	if len(net.watch) == 0 {
+		return 0, fmt.Errorf("nothing feeds %s, the module that feeds rx", rxFeeders[0])
+	}
+
 	start := net.Hash()
-	highTotal := 0
-	lowTotal := 0
+	presses := progress.Start(ctx, "presses", 0)
+	defer presses.Done()
 	buttonPresses := 0
 	for {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
 		buttonPresses++
+		presses.Add(1)
 		net.pressButton()
-		high, low, done := net.processQueue(buttonPresses)
-		highTotal += high
-		lowTotal += low
-		if done {
-			return buttonPresses
+		if _, _, done := net.processQueue(buttonPresses); done {
+			return buttonPresses, nil
 		}
-		if net.Hash() == start {
-			fmt.Println("cycle! ", highTotal, lowTotal)
-			return 0
+		periods := make([]int, 0, len(net.watch))
+		for _, first := range net.watch {
+			if first != 0 {
+				periods = append(periods, first)
+			}
 		}
-		if buttonPresses%1000000 == 0 {
-			fmt.Printf("%d presses, %d pulses\n", buttonPresses, highTotal+lowTotal)
+		if len(periods) == len(net.watch) {
+			slog.Debug("periods", "watch", net.watch)
+			n, err := numtheory.LCMAll(periods...)
+			if err != nil {
+				return 0, fmt.Errorf("combining periods %v: %w", net.watch, err)
+			}
+			return n, nil
+		}
+		if net.Hash() == start {
+			return 0, fmt.Errorf("cycled back to the start without a low pulse to rx after %d presses", buttonPresses)
 		}
 	}
 }
 
-func main() {
-	args := os.Args[1:]
-	name := "input"
-	if len(args) > 0 {
-		name = args[0]
-	}
-	f, err := os.Open(fmt.Sprintf("./data/%s.txt", name))
-	if err != nil {
-		log.Fatal(err)
-	}
-	b, err := io.ReadAll(f)
-	if err != nil {
-		log.Fatal(err)
-	}
-	lines := strings.Split(string(b), "\n")
-	fmt.Println(part1(lines))
-	fmt.Println(part2(lines))
+func init() {
+	runner.Register(runner.Day{Number: 20, Part1: part1, Part2: part2, Default: "input"})
 }
@@ -0,0 +1,61 @@
+package day20
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kentquirk/aoc2023/parsing"
+)
+
+func Test_newNetworkErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		lines     []string
+		line, col int
+		want      string
+	}{
+		{"no arrow", []string{"broadcaster -> a", "%a"}, 2, 1, `no "->" in "%a"`},
+		{"not a module", []string{"broadcaster -> a", "%a -> b", "?b -> a"}, 3, 1, `"?b" is not a module`},
+		{"twice", []string{"broadcaster -> a", "broadcaster -> a"}, 2, 0, "module broadcaster is listed twice"},
+		{"no broadcaster", []string{"%a -> b"}, 0, 0, "there is no broadcaster"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newNetwork(tt.lines)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("newNetwork() error = %v, want one saying %q", err, tt.want)
+			}
+			line, col := 0, 0
+			var pe *parsing.Error
+			if errors.As(err, &pe) {
+				line, col = pe.Line, pe.Col
+			}
+			if line != tt.line || col != tt.col {
+				t.Errorf("newNetwork() error at %d:%d, want %d:%d (%v)", line, col, tt.line, tt.col, err)
+			}
+		})
+	}
+}
+
+func Test_part2Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  string
+	}{
+		{"no rx feeder", []string{"broadcaster -> a, b, c", "%a -> b", "%b -> c", "%c -> inv", "&inv -> a"}, "want one conjunction feeding rx, found 0"},
+		{"flip-flop feeds rx", []string{"broadcaster -> a", "%a -> rx"}, "want a conjunction feeding rx"},
+		{"nothing to watch", []string{"broadcaster -> a", "&c -> rx"}, "nothing feeds c"},
+		{"cycle", []string{"broadcaster -> a", "%a -> b", "&b -> rx", "&c -> b"}, "cycled back to the start"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := part2(context.Background(), tt.lines)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("part2() error = %v, want one saying %q", err, tt.want)
+			}
+		})
+	}
+}
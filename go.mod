@@ -1,5 +1,5 @@
-module github.com/kentquirk/aoc2023/day20
+module github.com/kentquirk/aoc2023
 
-go 1.20
+go 1.21
 
 require github.com/dgryski/go-wyhash v0.0.0-20191203203029-c4841ae36371
@@ -0,0 +1,303 @@
+// Package grid is a rectangular grid of cells, for the puzzles that come as a
+// picture: pipes, mirrors, rocks on a dish and the like. Cells are addressed
+// by row and column from the top left.
+package grid
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kentquirk/aoc2023/parsing"
+)
+
+// Point is a row and column, or an offset from one.
+type Point struct {
+	Row, Col int
+}
+
+// Add returns p moved by q.
+func (p Point) Add(q Point) Point {
+	return Point{p.Row + q.Row, p.Col + q.Col}
+}
+
+// The four directions, as offsets.
+var (
+	Up    = Point{-1, 0}
+	Down  = Point{1, 0}
+	Left  = Point{0, -1}
+	Right = Point{0, 1}
+)
+
+// Dirs4 are the orthogonal directions, clockwise from Up.
+var Dirs4 = []Point{Up, Right, Down, Left}
+
+// Dirs8 are the orthogonal and diagonal directions, clockwise from Up.
+var Dirs8 = []Point{Up, {-1, 1}, Right, {1, 1}, Down, {1, -1}, Left, {-1, -1}}
+
+// Grid is a width by height grid of T.
+type Grid[T any] struct {
+	width  int
+	height int
+	cells  []T
+}
+
+// New returns a grid of the given size with every cell set to the zero T.
+func New[T any](width, height int) *Grid[T] {
+	return &Grid[T]{width: width, height: height, cells: make([]T, width*height)}
+}
+
+// Parse builds a grid from lines of text, one row per line, using f to turn
+// each character into a cell. Trailing blank lines are ignored; otherwise
+// every line must be the same length.
+func Parse[T any](lines []string, f func(r rune) T) (*Grid[T], error) {
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return New[T](0, 0), nil
+	}
+	width := utf8.RuneCountInString(lines[0])
+	g := New[T](width, len(lines))
+	for r, line := range lines {
+		if n := utf8.RuneCountInString(line); n != width {
+			return nil, &parsing.Error{Line: r + 1, Err: fmt.Errorf("%d wide, want %d", n, width)}
+		}
+		c := 0
+		for _, ch := range line {
+			g.cells[r*width+c] = f(ch)
+			c++
+		}
+	}
+	return g, nil
+}
+
+// MustParse is like Parse but panics if the lines aren't a rectangle.
+func MustParse[T any](lines []string, f func(r rune) T) *Grid[T] {
+	g, err := Parse(lines, f)
+	if err != nil {
+		panic("grid: " + err.Error())
+	}
+	return g
+}
+
+// Width returns the number of columns.
+func (g *Grid[T]) Width() int {
+	return g.width
+}
+
+// Height returns the number of rows.
+func (g *Grid[T]) Height() int {
+	return g.height
+}
+
+// In reports whether p is inside the grid.
+func (g *Grid[T]) In(p Point) bool {
+	return p.Row >= 0 && p.Row < g.height && p.Col >= 0 && p.Col < g.width
+}
+
+// Get returns the cell at p, and false if p is outside the grid.
+func (g *Grid[T]) Get(p Point) (T, bool) {
+	if !g.In(p) {
+		var zero T
+		return zero, false
+	}
+	return g.cells[p.Row*g.width+p.Col], true
+}
+
+// At returns the cell at p, or the zero T if p is outside the grid, which
+// saves checking the edges when looking around.
+func (g *Grid[T]) At(p Point) T {
+	v, _ := g.Get(p)
+	return v
+}
+
+// Set sets the cell at p. It panics if p is outside the grid.
+func (g *Grid[T]) Set(p Point, v T) {
+	if !g.In(p) {
+		panic(fmt.Sprintf("grid: %v is outside %dx%d", p, g.width, g.height))
+	}
+	g.cells[p.Row*g.width+p.Col] = v
+}
+
+// Row returns row r. It shares storage with the grid, so setting its cells
+// sets the grid's.
+func (g *Grid[T]) Row(r int) []T {
+	return g.cells[r*g.width : (r+1)*g.width : (r+1)*g.width]
+}
+
+// Column returns a copy of column c.
+func (g *Grid[T]) Column(c int) []T {
+	col := make([]T, g.height)
+	for r := range col {
+		col[r] = g.cells[r*g.width+c]
+	}
+	return col
+}
+
+// Points returns every point in the grid, a row at a time.
+func (g *Grid[T]) Points() []Point {
+	ps := make([]Point, 0, len(g.cells))
+	for r := 0; r < g.height; r++ {
+		for c := 0; c < g.width; c++ {
+			ps = append(ps, Point{r, c})
+		}
+	}
+	return ps
+}
+
+// Find returns the first point, a row at a time, whose cell matches.
+func (g *Grid[T]) Find(match func(T) bool) (Point, bool) {
+	for i, v := range g.cells {
+		if match(v) {
+			return Point{i / g.width, i % g.width}, true
+		}
+	}
+	return Point{}, false
+}
+
+func (g *Grid[T]) neighbors(p Point, dirs []Point) []Point {
+	var ns []Point
+	for _, d := range dirs {
+		if n := p.Add(d); g.In(n) {
+			ns = append(ns, n)
+		}
+	}
+	return ns
+}
+
+// Neighbors4 returns the points above, right of, below and left of p that
+// are inside the grid.
+func (g *Grid[T]) Neighbors4(p Point) []Point {
+	return g.neighbors(p, Dirs4)
+}
+
+// Neighbors8 is like Neighbors4 but includes the diagonals.
+func (g *Grid[T]) Neighbors8(p Point) []Point {
+	return g.neighbors(p, Dirs8)
+}
+
+// Clone returns a copy of the grid.
+func (g *Grid[T]) Clone() *Grid[T] {
+	return &Grid[T]{width: g.width, height: g.height, cells: append([]T(nil), g.cells...)}
+}
+
+// Transpose returns a new grid with rows and columns swapped.
+func (g *Grid[T]) Transpose() *Grid[T] {
+	t := New[T](g.height, g.width)
+	for r := 0; r < g.height; r++ {
+		for c := 0; c < g.width; c++ {
+			t.cells[c*t.width+r] = g.cells[r*g.width+c]
+		}
+	}
+	return t
+}
+
+// RotateCW returns a new grid turned a quarter turn clockwise, so the left
+// column becomes the top row.
+func (g *Grid[T]) RotateCW() *Grid[T] {
+	t := New[T](g.height, g.width)
+	for r := 0; r < g.height; r++ {
+		for c := 0; c < g.width; c++ {
+			t.cells[c*t.width+(g.height-1-r)] = g.cells[r*g.width+c]
+		}
+	}
+	return t
+}
+
+// RotateCCW returns a new grid turned a quarter turn anticlockwise, so the
+// top row becomes the left column.
+func (g *Grid[T]) RotateCCW() *Grid[T] {
+	t := New[T](g.height, g.width)
+	for r := 0; r < g.height; r++ {
+		for c := 0; c < g.width; c++ {
+			t.cells[(g.width-1-c)*t.width+r] = g.cells[r*g.width+c]
+		}
+	}
+	return t
+}
+
+// Hash returns a hash of the grid's size and contents, for spotting a grid
+// that has been seen before. Cells that are numbers, strings or bools are
+// hashed by value; anything else by how fmt prints it.
+func (g *Grid[T]) Hash() uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	put := func(u uint64) {
+		for i := range buf {
+			buf[i] = byte(u >> (8 * i))
+		}
+		h.Write(buf[:])
+	}
+	put(uint64(g.width))
+	put(uint64(g.height))
+	if b, ok := any(g.cells).([]byte); ok {
+		h.Write(b)
+		return h.Sum64()
+	}
+	for _, v := range g.cells {
+		rv := reflect.ValueOf(v)
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			put(uint64(rv.Int()))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			put(rv.Uint())
+		case reflect.Float32, reflect.Float64:
+			put(math.Float64bits(rv.Float()))
+		case reflect.Bool:
+			if rv.Bool() {
+				put(1)
+			} else {
+				put(0)
+			}
+		case reflect.String:
+			h.Write([]byte(rv.String()))
+			put(uint64(rv.Len()))
+		default:
+			fmt.Fprintf(h, "%v\x00", v)
+		}
+	}
+	return h.Sum64()
+}
+
+// Equal reports whether a and b are the same size with the same cells.
+func Equal[T comparable](a, b *Grid[T]) bool {
+	if a.width != b.width || a.height != b.height {
+		return false
+	}
+	for i := range a.cells {
+		if a.cells[i] != b.cells[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// String draws the grid a row per line. Cells that are a fmt.Stringer are
+// drawn with String, bytes and runes (including named types such as
+// "type cell byte") as the character, and anything else as fmt prints it.
+func (g *Grid[T]) String() string {
+	var sb strings.Builder
+	for r := 0; r < g.height; r++ {
+		for _, v := range g.Row(r) {
+			if s, ok := any(v).(fmt.Stringer); ok {
+				sb.WriteString(s.String())
+				continue
+			}
+			rv := reflect.ValueOf(v)
+			switch rv.Kind() {
+			case reflect.Uint8:
+				sb.WriteByte(byte(rv.Uint()))
+			case reflect.Int32:
+				sb.WriteRune(rune(rv.Int()))
+			default:
+				fmt.Fprint(&sb, v)
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
@@ -0,0 +1,162 @@
+package grid
+
+import (
+	"reflect"
+	"testing"
+)
+
+func parseTest(t *testing.T, lines ...string) *Grid[byte] {
+	t.Helper()
+	g, err := Parse(lines, func(r rune) byte { return byte(r) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func Test_Parse(t *testing.T) {
+	tests := []struct {
+		name       string
+		lines      []string
+		wantWidth  int
+		wantHeight int
+		wantErr    bool
+	}{
+		{"rectangle", []string{"abc", "def"}, 3, 2, false},
+		{"trailing blank lines", []string{"ab", "cd", "", ""}, 2, 2, false},
+		{"empty", nil, 0, 0, false},
+		{"ragged", []string{"abc", "de"}, 0, 0, true},
+		{"multibyte", []string{"┌┐", "└┘"}, 2, 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := Parse(tt.lines, func(r rune) rune { return r })
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if g.Width() != tt.wantWidth || g.Height() != tt.wantHeight {
+				t.Errorf("Parse() is %dx%d, want %dx%d", g.Width(), g.Height(), tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
+
+func Test_Access(t *testing.T) {
+	g := parseTest(t, "abc", "def")
+	tests := []struct {
+		name   string
+		p      Point
+		want   byte
+		wantOK bool
+	}{
+		{"top left", Point{0, 0}, 'a', true},
+		{"bottom right", Point{1, 2}, 'f', true},
+		{"above", Point{-1, 0}, 0, false},
+		{"right of", Point{0, 3}, 0, false},
+		{"below", Point{2, 1}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := g.Get(tt.p)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Get(%v) = %q, %v, want %q, %v", tt.p, got, ok, tt.want, tt.wantOK)
+			}
+			if got := g.At(tt.p); got != tt.want {
+				t.Errorf("At(%v) = %q, want %q", tt.p, got, tt.want)
+			}
+		})
+	}
+
+	g.Set(Point{1, 1}, 'E')
+	if got := string(g.Row(1)); got != "dEf" {
+		t.Errorf("Row(1) = %q after Set", got)
+	}
+	if got := string(g.Column(2)); got != "cf" {
+		t.Errorf("Column(2) = %q", got)
+	}
+	if p, ok := g.Find(func(b byte) bool { return b == 'E' }); !ok || p != (Point{1, 1}) {
+		t.Errorf("Find() = %v, %v", p, ok)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Set() outside the grid should panic")
+		}
+	}()
+	g.Set(Point{2, 0}, 'x')
+}
+
+func Test_Neighbors(t *testing.T) {
+	g := New[int](3, 3)
+	tests := []struct {
+		name string
+		f    func(Point) []Point
+		p    Point
+		want []Point
+	}{
+		{"4 middle", g.Neighbors4, Point{1, 1}, []Point{{0, 1}, {1, 2}, {2, 1}, {1, 0}}},
+		{"4 corner", g.Neighbors4, Point{0, 0}, []Point{{0, 1}, {1, 0}}},
+		{"8 middle", g.Neighbors8, Point{1, 1}, []Point{{0, 1}, {0, 2}, {1, 2}, {2, 2}, {2, 1}, {2, 0}, {1, 0}, {0, 0}}},
+		{"8 corner", g.Neighbors8, Point{2, 2}, []Point{{1, 2}, {2, 1}, {1, 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.f(tt.p); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Transform(t *testing.T) {
+	g := parseTest(t, "abc", "def")
+	tests := []struct {
+		name string
+		f    func() *Grid[byte]
+		want string
+	}{
+		{"transpose", g.Transpose, "ad\nbe\ncf\n"},
+		{"clockwise", g.RotateCW, "da\neb\nfc\n"},
+		{"anticlockwise", g.RotateCCW, "cf\nbe\nad\n"},
+		{"clone", g.Clone, "abc\ndef\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.f().String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if !Equal(g, g.RotateCW().RotateCW().RotateCW().RotateCW()) {
+		t.Error("four turns should get back to the start")
+	}
+	c := g.Clone()
+	c.Set(Point{0, 0}, 'z')
+	if g.At(Point{0, 0}) != 'a' {
+		t.Error("setting a clone changed the original")
+	}
+}
+
+type cell byte
+
+func Test_Hash(t *testing.T) {
+	a := MustParse([]string{"O.#", ".O."}, func(r rune) cell { return cell(r) })
+	b := a.Clone()
+	if a.Hash() != b.Hash() {
+		t.Error("equal grids should hash the same")
+	}
+	b.Set(Point{1, 2}, 'O')
+	if a.Hash() == b.Hash() {
+		t.Error("different grids should (almost always) hash differently")
+	}
+	if a.String() != "O.#\n.O.\n" {
+		t.Errorf("String() = %q", a.String())
+	}
+	wide := MustParse([]string{"O.#.O."}, func(r rune) cell { return cell(r) })
+	if a.Hash() == wide.Hash() {
+		t.Error("grids of different shapes should hash differently")
+	}
+}
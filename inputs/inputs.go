@@ -0,0 +1,159 @@
+// Package inputs finds the puzzle input for a day. Inputs are kept where
+// they always have been, in dayNN/data/input.txt, and anything missing can be
+// downloaded from the Advent of Code site (or anything that serves the same
+// paths) using the session cookie of a logged-in browser.
+package inputs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Provider returns the puzzle input for a day.
+type Provider interface {
+	Input(ctx context.Context, day int) ([]byte, error)
+}
+
+// Path returns where the input for day is kept below root.
+func Path(root string, day int) string {
+	return filepath.Join(root, fmt.Sprintf("day%02d", day), "data", "input.txt")
+}
+
+// Cache is a Provider that reads inputs from the day directories below Dir.
+// If an input is missing and Next is set, it asks Next for it and saves the
+// result so it doesn't have to ask again.
+type Cache struct {
+	Dir  string
+	Next Provider
+}
+
+// Input implements Provider.
+func (c *Cache) Input(ctx context.Context, day int) ([]byte, error) {
+	path := Path(c.Dir, day)
+	b, err := os.ReadFile(path)
+	if err == nil || !errors.Is(err, fs.ErrNotExist) || c.Next == nil {
+		return b, err
+	}
+	b, err = c.Next.Input(ctx, day)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, b, 0644); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// DefaultBaseURL is the site inputs are fetched from if none is given.
+const DefaultBaseURL = "https://adventofcode.com"
+
+// DefaultInterval is the least time between two requests to the server. The
+// inputs never change, so there is no reason to hurry.
+const DefaultInterval = 5 * time.Second
+
+// Fetcher is a Provider that downloads inputs over HTTP. Requests are made
+// no more often than once per Interval, no matter how many goroutines share
+// the Fetcher.
+type Fetcher struct {
+	// BaseURL is the server to fetch from; if empty, DefaultBaseURL.
+	BaseURL string
+	// Year is the event year.
+	Year int
+	// Session is the value of the "session" cookie.
+	Session string
+	// UserAgent is sent with each request so the site knows who to
+	// complain to.
+	UserAgent string
+	// Interval is the least time between requests; if zero,
+	// DefaultInterval. Use a negative value for no limit.
+	Interval time.Duration
+	// Client makes the requests; if nil, http.DefaultClient.
+	Client *http.Client
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+// NewFetcher returns a Fetcher for the 2023 event using the given session.
+func NewFetcher(session string) *Fetcher {
+	return &Fetcher{
+		Year:      2023,
+		Session:   session,
+		UserAgent: "github.com/kentquirk/aoc2023 by kentquirk",
+	}
+}
+
+// wait blocks until it is this caller's turn to make a request.
+func (f *Fetcher) wait(ctx context.Context) error {
+	interval := f.Interval
+	if interval == 0 {
+		interval = DefaultInterval
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if interval > 0 && !f.last.IsZero() {
+		if d := time.Until(f.last.Add(interval)); d > 0 {
+			t := time.NewTimer(d)
+			defer t.Stop()
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	f.last = time.Now()
+	return nil
+}
+
+// Input implements Provider.
+func (f *Fetcher) Input(ctx context.Context, day int) ([]byte, error) {
+	if f.Session == "" {
+		return nil, errors.New("no session cookie to fetch inputs with")
+	}
+	base := f.BaseURL
+	if base == "" {
+		base = DefaultBaseURL
+	}
+	url := fmt.Sprintf("%s/%d/day/%d/input", strings.TrimSuffix(base, "/"), f.Year, day)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.AddCookie(&http.Cookie{Name: "session", Value: f.Session})
+	if f.UserAgent != "" {
+		req.Header.Set("User-Agent", f.UserAgent)
+	}
+	if err := f.wait(ctx); err != nil {
+		return nil, err
+	}
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: %s: %s", url, resp.Status, strings.TrimSpace(string(b)))
+	}
+	return b, nil
+}
@@ -0,0 +1,119 @@
+package inputs
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kentquirk/aoc2023/inputs/inputstest"
+)
+
+func testFetcher(s *inputstest.Server, session string) *Fetcher {
+	f := NewFetcher(session)
+	f.BaseURL = s.URL
+	f.Client = s.Client()
+	f.Interval = -1
+	return f
+}
+
+func Test_Fetcher(t *testing.T) {
+	s := inputstest.NewServer(2023, "cookie", map[int]string{1: "1abc2\n", 7: "32T3K 765\n"})
+	defer s.Close()
+
+	tests := []struct {
+		name    string
+		session string
+		day     int
+		want    string
+		wantErr bool
+	}{
+		{"day1", "cookie", 1, "1abc2\n", false},
+		{"day7", "cookie", 7, "32T3K 765\n", false},
+		{"missing day", "cookie", 3, "", true},
+		{"bad session", "biscuit", 1, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testFetcher(s, tt.session).Input(context.Background(), tt.day)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Input() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Input() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewFetcher("").Input(context.Background(), 1); err == nil {
+		t.Error("Input() without a session should fail")
+	}
+}
+
+func Test_FetcherRateLimit(t *testing.T) {
+	s := inputstest.NewServer(2023, "cookie", map[int]string{1: "a", 2: "b", 3: "c"})
+	defer s.Close()
+	f := testFetcher(s, "cookie")
+	f.Interval = 50 * time.Millisecond
+
+	for day := 1; day <= 3; day++ {
+		if _, err := f.Input(context.Background(), day); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reqs := s.Requests()
+	if len(reqs) != 3 {
+		t.Fatalf("server saw %d requests, want 3", len(reqs))
+	}
+	for i := 1; i < len(reqs); i++ {
+		// allow a little slack for the clocks on either side of the request
+		if gap := reqs[i].Sub(reqs[i-1]); gap < f.Interval-5*time.Millisecond {
+			t.Errorf("requests %d and %d were only %v apart", i-1, i, gap)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := f.Input(ctx, 1); err == nil {
+		t.Error("Input() with a cancelled context should fail while waiting its turn")
+	}
+}
+
+func Test_Cache(t *testing.T) {
+	s := inputstest.NewServer(2023, "cookie", map[int]string{5: "seeds: 79 14 55 13"})
+	defer s.Close()
+	dir := t.TempDir()
+	c := &Cache{Dir: dir, Next: testFetcher(s, "cookie")}
+
+	for i := 0; i < 3; i++ {
+		got, err := c.Input(context.Background(), 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != "seeds: 79 14 55 13" {
+			t.Errorf("Input() = %q", got)
+		}
+	}
+	if n := len(s.Requests()); n != 1 {
+		t.Errorf("server saw %d requests, want 1", n)
+	}
+	b, err := os.ReadFile(Path(dir, 5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "seeds: 79 14 55 13" {
+		t.Errorf("cached input = %q", b)
+	}
+
+	if _, err := c.Input(context.Background(), 6); err == nil {
+		t.Error("Input() for a day the server doesn't have should fail")
+	}
+	if _, err := os.Stat(Path(dir, 6)); err == nil {
+		t.Error("a failed fetch should not be cached")
+	}
+
+	offline := &Cache{Dir: dir}
+	if _, err := offline.Input(context.Background(), 6); err == nil {
+		t.Error("Input() for a missing day with nothing to fall back on should fail")
+	}
+}
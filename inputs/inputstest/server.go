@@ -0,0 +1,65 @@
+// Package inputstest provides a fake puzzle server for testing code that
+// fetches inputs, in the same spirit as net/http/httptest.
+package inputstest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+)
+
+// Server serves /{year}/day/{day}/input for the inputs it was given, to
+// requests carrying the right session cookie.
+type Server struct {
+	*httptest.Server
+
+	year    int
+	session string
+	inputs  map[int]string
+
+	mu       sync.Mutex
+	requests []time.Time
+}
+
+// NewServer starts a Server for year that accepts session and serves inputs,
+// keyed by day. The caller should Close it when done.
+func NewServer(year int, session string, inputs map[int]string) *Server {
+	s := &Server{year: year, session: session, inputs: inputs}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.serve)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, time.Now())
+	s.mu.Unlock()
+
+	c, err := r.Cookie("session")
+	if err != nil || c.Value != s.session {
+		http.Error(w, "Puzzle inputs differ by user.  Please log in to get your puzzle input.", http.StatusBadRequest)
+		return
+	}
+	var year, day int
+	if _, err := fmt.Sscanf(r.URL.Path, "/%d/day/%d/input", &year, &day); err != nil || year != s.year {
+		http.NotFound(w, r)
+		return
+	}
+	in, ok := s.inputs[day]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	fmt.Fprint(w, in)
+}
+
+// Requests returns the time of every request the server has received, in
+// order.
+func (s *Server) Requests() []time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]time.Time(nil), s.requests...)
+}
@@ -0,0 +1,255 @@
+// Package interval does arithmetic on spans of integers, for the puzzles
+// that map or split whole ranges of values at once instead of one value at
+// a time.
+//
+// An Interval is half-open, [Lo, Hi), which makes lengths and splitting
+// come out without any +1s. Closed converts from the inclusive first-last
+// form that puzzle text tends to use.
+package interval
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Interval is the integers x with Lo <= x < Hi. It is empty if Hi <= Lo.
+type Interval struct {
+	Lo, Hi int
+}
+
+// Span returns the interval of count integers starting at start.
+func Span(start, count int) Interval {
+	return Interval{start, start + count}
+}
+
+// Closed returns the interval from first to last inclusive.
+func Closed(first, last int) Interval {
+	return Interval{first, last + 1}
+}
+
+// Empty reports whether the interval holds no integers.
+func (i Interval) Empty() bool {
+	return i.Hi <= i.Lo
+}
+
+// Len returns the number of integers in the interval.
+func (i Interval) Len() int {
+	if i.Empty() {
+		return 0
+	}
+	return i.Hi - i.Lo
+}
+
+// Last returns the largest integer in a non-empty interval.
+func (i Interval) Last() int {
+	return i.Hi - 1
+}
+
+// Contains reports whether x is in the interval.
+func (i Interval) Contains(x int) bool {
+	return i.Lo <= x && x < i.Hi
+}
+
+// Overlaps reports whether the intervals have any integer in common.
+func (i Interval) Overlaps(o Interval) bool {
+	return !i.Intersect(o).Empty()
+}
+
+// Intersect returns the integers in both intervals. The result may be empty.
+func (i Interval) Intersect(o Interval) Interval {
+	return Interval{max(i.Lo, o.Lo), min(i.Hi, o.Hi)}
+}
+
+// Subtract returns what is left of i once o is taken out: none, one or two
+// non-empty intervals, in order.
+func (i Interval) Subtract(o Interval) []Interval {
+	if i.Empty() {
+		return nil
+	}
+	if !i.Overlaps(o) {
+		return []Interval{i}
+	}
+	var left []Interval
+	if below := (Interval{i.Lo, o.Lo}); !below.Empty() {
+		left = append(left, below)
+	}
+	if above := (Interval{o.Hi, i.Hi}); !above.Empty() {
+		left = append(left, above)
+	}
+	return left
+}
+
+// SplitAt divides the interval into the part below x and the part from x
+// up. Either may be empty.
+func (i Interval) SplitAt(x int) (Interval, Interval) {
+	x = min(max(x, i.Lo), max(i.Hi, i.Lo))
+	return Interval{i.Lo, x}, Interval{x, i.Hi}
+}
+
+// Shift returns the interval moved up by d.
+func (i Interval) Shift(d int) Interval {
+	return Interval{i.Lo + d, i.Hi + d}
+}
+
+func (i Interval) String() string {
+	return fmt.Sprintf("[%d,%d)", i.Lo, i.Hi)
+}
+
+// Set is a set of integers held as sorted, disjoint, non-adjacent, non-empty
+// intervals. The zero Set is empty. Sets are values: the methods return new
+// sets rather than changing their receiver.
+type Set struct {
+	ivs []Interval
+}
+
+// NewSet returns the set of integers in any of ivs.
+func NewSet(ivs ...Interval) Set {
+	var s []Interval
+	for _, i := range ivs {
+		if !i.Empty() {
+			s = append(s, i)
+		}
+	}
+	slices.SortFunc(s, func(a, b Interval) int { return a.Lo - b.Lo })
+	return Set{coalesce(s)}
+}
+
+// coalesce merges overlapping and adjacent intervals in a sorted list.
+func coalesce(s []Interval) []Interval {
+	var out []Interval
+	for _, i := range s {
+		if n := len(out); n > 0 && i.Lo <= out[n-1].Hi {
+			out[n-1].Hi = max(out[n-1].Hi, i.Hi)
+			continue
+		}
+		out = append(out, i)
+	}
+	return out
+}
+
+// Intervals returns the set's intervals in order.
+func (s Set) Intervals() []Interval {
+	return slices.Clone(s.ivs)
+}
+
+// Empty reports whether the set has no integers in it.
+func (s Set) Empty() bool {
+	return len(s.ivs) == 0
+}
+
+// Len returns the number of integers in the set.
+func (s Set) Len() int {
+	n := 0
+	for _, i := range s.ivs {
+		n += i.Len()
+	}
+	return n
+}
+
+// Min returns the smallest integer in the set, and false if it is empty.
+func (s Set) Min() (int, bool) {
+	if s.Empty() {
+		return 0, false
+	}
+	return s.ivs[0].Lo, true
+}
+
+// Contains reports whether x is in the set.
+func (s Set) Contains(x int) bool {
+	i, found := slices.BinarySearchFunc(s.ivs, x, func(iv Interval, x int) int {
+		switch {
+		case iv.Hi <= x:
+			return -1
+		case iv.Lo > x:
+			return 1
+		}
+		return 0
+	})
+	return found && s.ivs[i].Contains(x)
+}
+
+// Add returns the set with i added.
+func (s Set) Add(i Interval) Set {
+	return s.Union(NewSet(i))
+}
+
+// Union returns the integers in either set.
+func (s Set) Union(o Set) Set {
+	return NewSet(append(slices.Clone(s.ivs), o.ivs...)...)
+}
+
+// Intersect returns the integers in both sets.
+func (s Set) Intersect(o Set) Set {
+	var out []Interval
+	a, b := s.ivs, o.ivs
+	for len(a) > 0 && len(b) > 0 {
+		if i := a[0].Intersect(b[0]); !i.Empty() {
+			out = append(out, i)
+		}
+		if a[0].Hi < b[0].Hi {
+			a = a[1:]
+		} else {
+			b = b[1:]
+		}
+	}
+	return Set{out}
+}
+
+// Subtract returns the integers in s but not in o.
+func (s Set) Subtract(o Set) Set {
+	var out []Interval
+	b := o.ivs
+	for _, i := range s.ivs {
+		for len(b) > 0 && b[0].Hi <= i.Lo {
+			b = b[1:]
+		}
+		rest := i
+		for _, cut := range b {
+			if cut.Lo >= rest.Hi {
+				break
+			}
+			below, above := rest.SplitAt(cut.Lo)
+			if !below.Empty() {
+				out = append(out, below)
+			}
+			_, rest = above.SplitAt(cut.Hi)
+		}
+		if !rest.Empty() {
+			out = append(out, rest)
+		}
+	}
+	return Set{out}
+}
+
+// SplitAt divides the set into the integers below x and those from x up.
+func (s Set) SplitAt(x int) (Set, Set) {
+	var below, above []Interval
+	for _, i := range s.ivs {
+		b, a := i.SplitAt(x)
+		if !b.Empty() {
+			below = append(below, b)
+		}
+		if !a.Empty() {
+			above = append(above, a)
+		}
+	}
+	return Set{below}, Set{above}
+}
+
+// Shift returns the set moved up by d.
+func (s Set) Shift(d int) Set {
+	out := make([]Interval, len(s.ivs))
+	for n, i := range s.ivs {
+		out[n] = i.Shift(d)
+	}
+	return Set{out}
+}
+
+func (s Set) String() string {
+	parts := make([]string, len(s.ivs))
+	for n, i := range s.ivs {
+		parts[n] = i.String()
+	}
+	return "{" + strings.Join(parts, " ") + "}"
+}
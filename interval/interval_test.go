@@ -0,0 +1,187 @@
+package interval
+
+import (
+	"math/bits"
+	"reflect"
+	"testing"
+)
+
+// The property tests check every operation against a bitmask model of every
+// subset of a small universe of integers. Bit x+offset stands for x.
+const (
+	universe = 7
+	offset   = 20
+)
+
+type mask uint64
+
+func bit(x int) mask {
+	return 1 << uint(x+offset)
+}
+
+func maskOf(s Set) mask {
+	var m mask
+	for _, i := range s.ivs {
+		for x := i.Lo; x < i.Hi; x++ {
+			m |= bit(x)
+		}
+	}
+	return m
+}
+
+// subsets returns every subset of [0, universe) twice over: once built from
+// its runs, and once from single points, so that NewSet has to coalesce.
+func subsets() map[mask][]Set {
+	all := make(map[mask][]Set)
+	for m := 0; m < 1<<universe; m++ {
+		var runs, points []Interval
+		for x := 0; x < universe; x++ {
+			if m&(1<<x) == 0 {
+				continue
+			}
+			points = append(points, Span(x, 1))
+			if n := len(runs); n > 0 && runs[n-1].Hi == x {
+				runs[n-1].Hi++
+			} else {
+				runs = append(runs, Span(x, 1))
+			}
+		}
+		// reverse the points so they need sorting too
+		for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+			points[i], points[j] = points[j], points[i]
+		}
+		key := mask(m) << offset
+		all[key] = []Set{NewSet(runs...), NewSet(points...)}
+	}
+	return all
+}
+
+// canonical checks the invariant every Set should keep.
+func canonical(t *testing.T, s Set) {
+	t.Helper()
+	for n, i := range s.ivs {
+		if i.Empty() {
+			t.Fatalf("%v holds an empty interval", s)
+		}
+		if n > 0 && s.ivs[n-1].Hi >= i.Lo {
+			t.Fatalf("%v is not sorted, disjoint and coalesced", s)
+		}
+	}
+}
+
+func Test_SetProperties(t *testing.T) {
+	all := subsets()
+	for ma, as := range all {
+		for _, a := range as {
+			canonical(t, a)
+			if got := maskOf(a); got != ma {
+				t.Fatalf("NewSet built %v for %b", a, ma>>offset)
+			}
+			if a.Len() != bits.OnesCount64(uint64(ma)) {
+				t.Errorf("%v.Len() = %d", a, a.Len())
+			}
+			for x := -1; x <= universe; x++ {
+				if a.Contains(x) != (ma&bit(x) != 0) {
+					t.Errorf("%v.Contains(%d) = %v", a, x, a.Contains(x))
+				}
+				below, above := a.SplitAt(x)
+				canonical(t, below)
+				canonical(t, above)
+				if maskOf(below) != ma&(bit(x)-1) || maskOf(above) != ma&^(bit(x)-1) {
+					t.Errorf("%v.SplitAt(%d) = %v, %v", a, x, below, above)
+				}
+			}
+			for d := -3; d <= 3; d++ {
+				s := a.Shift(d)
+				canonical(t, s)
+				want := ma << uint(d+3) >> 3
+				if maskOf(s) != want {
+					t.Errorf("%v.Shift(%d) = %v", a, d, s)
+				}
+			}
+			if m, ok := a.Min(); ok != (ma != 0) || (ok && bit(m) != ma&-ma) {
+				t.Errorf("%v.Min() = %d, %v", a, m, ok)
+			}
+		}
+		for mo, os := range all {
+			a, o := as[0], os[len(os)-1]
+			for _, tt := range []struct {
+				name string
+				got  Set
+				want mask
+			}{
+				{"Union", a.Union(o), ma | mo},
+				{"Intersect", a.Intersect(o), ma & mo},
+				{"Subtract", a.Subtract(o), ma &^ mo},
+			} {
+				canonical(t, tt.got)
+				if maskOf(tt.got) != tt.want {
+					t.Errorf("%v.%s(%v) = %v", a, tt.name, o, tt.got)
+				}
+			}
+			// the operations agree with each other
+			if !reflect.DeepEqual(a.Subtract(o).Union(a.Intersect(o)), a) {
+				t.Errorf("%v minus %v, plus their intersection, isn't %v", a, o, a)
+			}
+		}
+	}
+}
+
+func Test_IntervalProperties(t *testing.T) {
+	var ivs []Interval
+	for lo := -1; lo <= universe; lo++ {
+		for hi := lo - 1; hi <= universe; hi++ {
+			ivs = append(ivs, Interval{lo, hi})
+		}
+	}
+	m := func(i Interval) mask { return maskOf(NewSet(i)) }
+	for _, a := range ivs {
+		if a.Len() != bits.OnesCount64(uint64(m(a))) {
+			t.Errorf("%v.Len() = %d", a, a.Len())
+		}
+		for _, o := range ivs {
+			if got := m(a.Intersect(o)); got != m(a)&m(o) {
+				t.Errorf("%v.Intersect(%v) = %v", a, o, a.Intersect(o))
+			}
+			if a.Overlaps(o) != (m(a)&m(o) != 0) {
+				t.Errorf("%v.Overlaps(%v) = %v", a, o, a.Overlaps(o))
+			}
+			pieces := a.Subtract(o)
+			if got := maskOf(Set{pieces}); got != m(a)&^m(o) || len(pieces) > 2 {
+				t.Errorf("%v.Subtract(%v) = %v", a, o, pieces)
+			}
+			canonical(t, Set{pieces})
+		}
+		for x := -2; x <= universe+1; x++ {
+			below, above := a.SplitAt(x)
+			if m(below) != m(a)&(bit(x)-1) || m(above) != m(a)&^(bit(x)-1) {
+				t.Errorf("%v.SplitAt(%d) = %v, %v", a, x, below, above)
+			}
+			if a.Contains(x) != (m(a)&bit(x) != 0) {
+				t.Errorf("%v.Contains(%d) = %v", a, x, a.Contains(x))
+			}
+		}
+	}
+}
+
+func Test_Constructors(t *testing.T) {
+	tests := []struct {
+		name string
+		got  Interval
+		want Interval
+	}{
+		{"span", Span(79, 14), Interval{79, 93}},
+		{"closed", Closed(3, 8), Interval{3, 9}},
+		{"shift", Span(50, 48).Shift(2), Interval{52, 100}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+	if s := NewSet(Span(1, 2), Span(10, 1), Span(3, 2)).String(); s != "{[1,5) [10,11)}" {
+		t.Errorf("String() = %s", s)
+	}
+}
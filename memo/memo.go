@@ -0,0 +1,132 @@
+// Package memo caches the results of expensive functions. Keys are compared
+// exactly, so unlike a cache keyed on a hash of the arguments, two different
+// calls can never share an answer.
+//
+// A function whose arguments aren't comparable, such as one taking a slice,
+// can still be memoized with Keyed by supplying a function that turns the
+// arguments into a comparable key.
+package memo
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+)
+
+// Stats describe how well a Cache is doing.
+type Stats struct {
+	Hits      int64
+	Misses    int64
+	Evictions int64
+	Len       int
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("%d hits, %d misses, %d evictions, %d entries", s.Hits, s.Misses, s.Evictions, s.Len)
+}
+
+// Cache maps keys to values, optionally holding no more than a fixed number
+// of entries by evicting the least recently used. It is safe for concurrent
+// use.
+type Cache[K comparable, V any] struct {
+	mu      sync.Mutex
+	max     int
+	entries map[K]*list.Element
+	lru     *list.List
+	stats   Stats
+}
+
+type entry[K comparable, V any] struct {
+	key K
+	val V
+}
+
+// New returns an empty cache holding at most max entries, or any number if
+// max is zero or less.
+func New[K comparable, V any](max int) *Cache[K, V] {
+	return &Cache[K, V]{
+		max:     max,
+		entries: make(map[K]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// Get returns the value cached for k, and whether there was one.
+func (c *Cache[K, V]) Get(k K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[k]; ok {
+		c.stats.Hits++
+		c.lru.MoveToFront(e)
+		return e.Value.(*entry[K, V]).val, true
+	}
+	c.stats.Misses++
+	var zero V
+	return zero, false
+}
+
+// Put caches v for k, evicting the least recently used entry if the cache
+// is full.
+func (c *Cache[K, V]) Put(k K, v V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[k]; ok {
+		e.Value.(*entry[K, V]).val = v
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.entries[k] = c.lru.PushFront(&entry[K, V]{k, v})
+	if c.max > 0 && c.lru.Len() > c.max {
+		e := c.lru.Back()
+		c.lru.Remove(e)
+		delete(c.entries, e.Value.(*entry[K, V]).key)
+		c.stats.Evictions++
+	}
+}
+
+// Len returns the number of entries in the cache.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Stats returns the cache's statistics so far.
+func (c *Cache[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.Len = c.lru.Len()
+	return s
+}
+
+// Reset empties the cache and zeroes its statistics.
+func (c *Cache[K, V]) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[K]*list.Element)
+	c.lru.Init()
+	c.stats = Stats{}
+}
+
+// Func returns f memoized in c. The cache isn't locked while f runs, so f
+// may call the memoized function recursively; if two goroutines ask for the
+// same missing key at once, both compute it.
+func Func[K comparable, V any](c *Cache[K, V], f func(K) V) func(K) V {
+	return Keyed(c, func(k K) K { return k }, f)
+}
+
+// Keyed is like Func for a function whose argument isn't comparable. key
+// turns the argument into the key to cache its result under; it must give
+// different keys to arguments that could have different results.
+func Keyed[A any, K comparable, V any](c *Cache[K, V], key func(A) K, f func(A) V) func(A) V {
+	return func(a A) V {
+		k := key(a)
+		if v, ok := c.Get(k); ok {
+			return v
+		}
+		v := f(a)
+		c.Put(k, v)
+		return v
+	}
+}
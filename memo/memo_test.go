@@ -0,0 +1,119 @@
+package memo
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func Test_Cache(t *testing.T) {
+	c := New[string, int](2)
+	c.Put("a", 1)
+	c.Put("b", 2)
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Errorf("Get(a) = %d, %v", v, ok)
+	}
+	// b is now the least recently used, so it goes
+	c.Put("c", 3)
+	tests := []struct {
+		key    string
+		want   int
+		wantOK bool
+	}{
+		{"a", 1, true},
+		{"b", 0, false},
+		{"c", 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if v, ok := c.Get(tt.key); v != tt.want || ok != tt.wantOK {
+				t.Errorf("Get(%s) = %d, %v, want %d, %v", tt.key, v, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+	want := Stats{Hits: 3, Misses: 1, Evictions: 1, Len: 2}
+	if got := c.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+	c.Reset()
+	if got := c.Stats(); got != (Stats{}) {
+		t.Errorf("Stats() after Reset = %+v", got)
+	}
+}
+
+func Test_Func(t *testing.T) {
+	c := New[int, int](0)
+	calls := 0
+	var fib func(int) int
+	fib = Func(c, func(n int) int {
+		calls++
+		if n < 2 {
+			return n
+		}
+		return fib(n-1) + fib(n-2)
+	})
+	if got := fib(90); got != 2880067194370816120 {
+		t.Errorf("fib(90) = %d", got)
+	}
+	if calls != 91 {
+		t.Errorf("fib(90) made %d calls, want 91", calls)
+	}
+}
+
+func Test_Keyed(t *testing.T) {
+	// keys that a hash of truncated bytes would have mixed up: 1 and 257
+	c := New[string, int](0)
+	sum := Keyed(c, func(ns []int) string {
+		var sb strings.Builder
+		for _, n := range ns {
+			sb.WriteString(string(rune(n)))
+		}
+		return sb.String()
+	}, func(ns []int) int {
+		total := 0
+		for _, n := range ns {
+			total += n
+		}
+		return total
+	})
+	tests := []struct {
+		name string
+		ns   []int
+		want int
+	}{
+		{"small", []int{1, 2}, 3},
+		{"big", []int{257, 2}, 259},
+		{"small again", []int{1, 2}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sum(tt.ns); got != tt.want {
+				t.Errorf("sum(%v) = %d, want %d", tt.ns, got, tt.want)
+			}
+		})
+	}
+	if s := c.Stats(); s.Hits != 1 || s.Misses != 2 {
+		t.Errorf("Stats() = %+v", s)
+	}
+}
+
+func Test_Concurrent(t *testing.T) {
+	c := New[int, int](50)
+	square := Func(c, func(n int) int { return n * n })
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				if got := square(i % 100); got != (i%100)*(i%100) {
+					t.Errorf("square(%d) = %d", i%100, got)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if n := c.Len(); n > 50 {
+		t.Errorf("Len() = %d, over the bound of 50", n)
+	}
+}
@@ -0,0 +1,226 @@
+// Package numtheory has the bits of number theory the puzzles lean on when
+// several things have to line up: GCD and LCM, the Chinese Remainder Theorem
+// and modular arithmetic.
+//
+// The int versions check for overflow rather than quietly wrapping; when an
+// answer really is too big for an int, the Big versions give it exactly.
+package numtheory
+
+import (
+	"errors"
+	"math/big"
+	"math/bits"
+)
+
+var (
+	// ErrOverflow is returned when an answer doesn't fit in an int.
+	ErrOverflow = errors.New("numtheory: result overflows int")
+	// ErrNoSolution is returned by CRT when the congruences contradict
+	// each other.
+	ErrNoSolution = errors.New("numtheory: congruences have no solution")
+	// ErrNoInverse is returned by ModInv when a has no inverse mod m.
+	ErrNoInverse = errors.New("numtheory: no modular inverse")
+	// ErrModulus is returned when a modulus isn't positive.
+	ErrModulus = errors.New("numtheory: modulus must be positive")
+)
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// GCD returns the greatest common divisor of a and b, which is never
+// negative. GCD(0, 0) is 0.
+func GCD(a, b int) int {
+	// work in uint so that the most negative int doesn't overflow
+	x, y := uint(abs(a)), uint(abs(b))
+	for y != 0 {
+		x, y = y, x%y
+	}
+	return int(x)
+}
+
+// GCDAll returns the greatest common divisor of all of ns.
+func GCDAll(ns ...int) int {
+	g := 0
+	for _, n := range ns {
+		g = GCD(g, n)
+	}
+	return g
+}
+
+// LCM returns the least common multiple of a and b, which is never
+// negative, or ErrOverflow. LCM(0, n) is 0.
+func LCM(a, b int) (int, error) {
+	if a == 0 || b == 0 {
+		return 0, nil
+	}
+	a, b = abs(a), abs(b)
+	if a < 0 || b < 0 {
+		return 0, ErrOverflow
+	}
+	return mul(a/GCD(a, b), b)
+}
+
+// LCMAll returns the least common multiple of all of ns, or ErrOverflow.
+// The LCM of nothing is 1.
+func LCMAll(ns ...int) (int, error) {
+	l := 1
+	for _, n := range ns {
+		var err error
+		if l, err = LCM(l, n); err != nil {
+			return 0, err
+		}
+	}
+	return l, nil
+}
+
+// BigLCM is LCMAll without the overflow.
+func BigLCM(ns ...int) *big.Int {
+	l := big.NewInt(1)
+	for _, n := range ns {
+		if n == 0 {
+			return new(big.Int)
+		}
+		b := new(big.Int).Abs(big.NewInt(int64(n)))
+		g := new(big.Int).GCD(nil, nil, l, b)
+		l.Mul(l, b.Quo(b, g))
+	}
+	return l
+}
+
+// mul returns a*b for non-negative a and b, or ErrOverflow.
+func mul(a, b int) (int, error) {
+	hi, lo := bits.Mul64(uint64(a), uint64(b))
+	if hi != 0 || lo > uint64(maxInt) {
+		return 0, ErrOverflow
+	}
+	return int(lo), nil
+}
+
+const maxInt = int(^uint(0) >> 1)
+
+// ExtendedGCD returns g = GCD(a, b) and x and y such that a*x + b*y = g.
+func ExtendedGCD(a, b int) (g, x, y int) {
+	oldR, r := a, b
+	oldS, s := 1, 0
+	oldT, t := 0, 1
+	for r != 0 {
+		q := oldR / r
+		oldR, r = r, oldR-q*r
+		oldS, s = s, oldS-q*s
+		oldT, t = t, oldT-q*t
+	}
+	if oldR < 0 {
+		return -oldR, -oldS, -oldT
+	}
+	return oldR, oldS, oldT
+}
+
+// Mod returns a mod m in [0, m), unlike %, which keeps the sign of a.
+func Mod(a, m int) int {
+	r := a % m
+	if r < 0 {
+		r += m
+	}
+	return r
+}
+
+// MulMod returns a*b mod m without overflowing, for positive m.
+func MulMod(a, b, m int) int {
+	a, b = Mod(a, m), Mod(b, m)
+	hi, lo := bits.Mul64(uint64(a), uint64(b))
+	return int(bits.Rem64(hi, lo, uint64(m)))
+}
+
+// ModPow returns b to the power e mod m, for e >= 0 and positive m.
+func ModPow(b, e, m int) int {
+	result := 1 % m
+	b = Mod(b, m)
+	for e > 0 {
+		if e&1 == 1 {
+			result = MulMod(result, b, m)
+		}
+		b = MulMod(b, b, m)
+		e >>= 1
+	}
+	return result
+}
+
+// ModInv returns x in [0, m) with a*x = 1 mod m, or ErrNoInverse if a and m
+// have a common factor.
+func ModInv(a, m int) (int, error) {
+	if m <= 0 {
+		return 0, ErrModulus
+	}
+	g, x, _ := ExtendedGCD(Mod(a, m), m)
+	if g != 1 {
+		return 0, ErrNoInverse
+	}
+	return Mod(x, m), nil
+}
+
+// CRT solves the congruences n = residues[i] mod moduli[i]. The moduli
+// needn't be coprime. It returns the smallest solution n >= 0 and the LCM
+// of the moduli, every solution being n plus a multiple of it. It returns
+// ErrNoSolution if there is none, and ErrOverflow if the LCM doesn't fit in
+// an int, in which case BigCRT will do.
+func CRT(residues, moduli []int) (n, m int, err error) {
+	if len(residues) != len(moduli) {
+		return 0, 0, errors.New("numtheory: need a modulus for each residue")
+	}
+	n, m = 0, 1
+	for i, mi := range moduli {
+		if mi <= 0 {
+			return 0, 0, ErrModulus
+		}
+		ri := Mod(residues[i], mi)
+		// n + m*k = ri (mod mi), so m*k = ri - n (mod mi)
+		g, p, _ := ExtendedGCD(m, mi)
+		diff := ri - Mod(n, mi)
+		if diff%g != 0 {
+			return 0, 0, ErrNoSolution
+		}
+		l, err := mul(m/g, mi)
+		if err != nil {
+			return 0, 0, err
+		}
+		step := mi / g
+		k := MulMod(diff/g, p, step)
+		// n < m and k < step, so n + m*k < m*step = l, which fits
+		n += MulMod(m, k, l)
+		m = l
+	}
+	return n, m, nil
+}
+
+// BigCRT is CRT without the overflow.
+func BigCRT(residues, moduli []*big.Int) (n, m *big.Int, err error) {
+	if len(residues) != len(moduli) {
+		return nil, nil, errors.New("numtheory: need a modulus for each residue")
+	}
+	n, m = new(big.Int), big.NewInt(1)
+	for i, mi := range moduli {
+		if mi.Sign() <= 0 {
+			return nil, nil, ErrModulus
+		}
+		ri := new(big.Int).Mod(residues[i], mi)
+		p := new(big.Int)
+		g := new(big.Int).GCD(p, nil, m, mi)
+		diff := new(big.Int).Sub(ri, new(big.Int).Mod(n, mi))
+		q, r := new(big.Int).QuoRem(diff, g, new(big.Int))
+		if r.Sign() != 0 {
+			return nil, nil, ErrNoSolution
+		}
+		step := new(big.Int).Quo(mi, g)
+		k := q.Mul(q, p)
+		k.Mod(k, step)
+		l := new(big.Int).Mul(new(big.Int).Quo(m, g), mi)
+		n.Add(n, k.Mul(k, m))
+		n.Mod(n, l)
+		m = l
+	}
+	return n, m, nil
+}
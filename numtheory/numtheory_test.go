@@ -0,0 +1,143 @@
+package numtheory
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func Test_GCDLCM(t *testing.T) {
+	tests := []struct {
+		name    string
+		ns      []int
+		wantGCD int
+		wantLCM int
+		wantErr error
+	}{
+		{"pair", []int{12, 18}, 6, 36, nil},
+		{"negative", []int{-4, 6}, 2, 12, nil},
+		{"zero", []int{0, 5}, 5, 0, nil},
+		{"nothing", nil, 0, 1, nil},
+		{"day08 periods", []int{16343, 16897, 20221, 18559, 11911, 21883}, 277, 16563603485021, nil},
+		{"day20 periods", []int{3803, 3877, 3889, 3917}, 1, 224602011344203, nil},
+		{"overflow", []int{math.MaxInt64 - 1, math.MaxInt64 - 2}, 1, 0, ErrOverflow},
+		{"most negative", []int{math.MinInt64, 3}, 1, 0, ErrOverflow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GCDAll(tt.ns...); got != tt.wantGCD {
+				t.Errorf("GCDAll() = %d, want %d", got, tt.wantGCD)
+			}
+			got, err := LCMAll(tt.ns...)
+			if !errors.Is(err, tt.wantErr) || got != tt.wantLCM {
+				t.Errorf("LCMAll() = %d, %v, want %d, %v", got, err, tt.wantLCM, tt.wantErr)
+			}
+			if err == nil {
+				if b := BigLCM(tt.ns...); b.Cmp(big.NewInt(int64(got))) != 0 {
+					t.Errorf("BigLCM() = %v, want %d", b, got)
+				}
+			}
+		})
+	}
+
+	want, _ := new(big.Int).SetString("85070591730234615819726791673668173830", 10)
+	if got := BigLCM(math.MaxInt64-1, math.MaxInt64-2); got.Cmp(want) != 0 {
+		t.Errorf("BigLCM() = %v, want %v", got, want)
+	}
+}
+
+func Test_ExtendedGCD(t *testing.T) {
+	tests := []struct {
+		a, b int
+	}{
+		{240, 46}, {46, 240}, {-240, 46}, {17, 0}, {0, 17}, {1 << 40, 3 << 20},
+	}
+	for _, tt := range tests {
+		g, x, y := ExtendedGCD(tt.a, tt.b)
+		if g != GCD(tt.a, tt.b) || tt.a*x+tt.b*y != g {
+			t.Errorf("ExtendedGCD(%d, %d) = %d, %d, %d", tt.a, tt.b, g, x, y)
+		}
+	}
+}
+
+func Test_Modular(t *testing.T) {
+	tests := []struct {
+		name string
+		got  int
+		want int
+	}{
+		{"mod negative", Mod(-7, 5), 3},
+		{"mulmod big", MulMod(math.MaxInt64-1, math.MaxInt64-1, math.MaxInt64), 1},
+		{"modpow", ModPow(4, 13, 497), 445},
+		{"modpow fermat", ModPow(2, 1_000_000_006, 1_000_000_007), 1},
+		{"modpow mod 1", ModPow(5, 0, 1), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %d, want %d", tt.got, tt.want)
+			}
+		})
+	}
+
+	if x, err := ModInv(3, 11); err != nil || x != 4 {
+		t.Errorf("ModInv(3, 11) = %d, %v", x, err)
+	}
+	if x, err := ModInv(-3, 11); err != nil || x != 7 {
+		t.Errorf("ModInv(-3, 11) = %d, %v", x, err)
+	}
+	if _, err := ModInv(6, 9); !errors.Is(err, ErrNoInverse) {
+		t.Errorf("ModInv(6, 9) error = %v", err)
+	}
+}
+
+func Test_CRT(t *testing.T) {
+	tests := []struct {
+		name     string
+		residues []int
+		moduli   []int
+		wantN    int
+		wantM    int
+		wantErr  error
+	}{
+		{"coprime", []int{2, 3, 2}, []int{3, 5, 7}, 23, 105, nil},
+		{"not coprime", []int{3, 5}, []int{4, 6}, 11, 12, nil},
+		{"contradiction", []int{1, 2}, []int{4, 6}, 0, 0, ErrNoSolution},
+		{"negative residue", []int{-1, -1}, []int{4, 6}, 11, 12, nil},
+		{"all zero", []int{0, 0, 0}, []int{3803, 3877, 3889}, 0, 3803 * 3877 * 3889, nil},
+		{"bad modulus", []int{1}, []int{0}, 0, 0, ErrModulus},
+		{"overflow", []int{1, 2}, []int{math.MaxInt64 - 1, math.MaxInt64 - 2}, 0, 0, ErrOverflow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, m, err := CRT(tt.residues, tt.moduli)
+			if !errors.Is(err, tt.wantErr) || n != tt.wantN || m != tt.wantM {
+				t.Errorf("CRT() = %d, %d, %v, want %d, %d, %v", n, m, err, tt.wantN, tt.wantM, tt.wantErr)
+			}
+			var rs, ms []*big.Int
+			for i := range tt.residues {
+				rs = append(rs, big.NewInt(int64(tt.residues[i])))
+				ms = append(ms, big.NewInt(int64(tt.moduli[i])))
+			}
+			bn, bm, berr := BigCRT(rs, ms)
+			if tt.wantErr == ErrOverflow {
+				if berr != nil {
+					t.Fatalf("BigCRT() error = %v", berr)
+				}
+				for i := range rs {
+					if new(big.Int).Mod(bn, ms[i]).Cmp(new(big.Int).Mod(rs[i], ms[i])) != 0 {
+						t.Errorf("BigCRT() = %v doesn't satisfy congruence %d", bn, i)
+					}
+				}
+				return
+			}
+			if !errors.Is(berr, tt.wantErr) {
+				t.Fatalf("BigCRT() error = %v, want %v", berr, tt.wantErr)
+			}
+			if berr == nil && (bn.Int64() != int64(n) || bm.Int64() != int64(m)) {
+				t.Errorf("BigCRT() = %v, %v, want %d, %d", bn, bm, n, m)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package parsing
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Bind matches re against s and stores its named groups in the fields of
+// the struct dst points to. A field gets the group named in its `parse` tag,
+// or if it has none, the group with its name in lower case. Fields may be
+// strings, any size of int or uint, or bool. Groups that didn't take part in
+// the match leave their fields alone.
+//
+//	var m struct {
+//		Key   string
+//		Value int
+//	}
+//	err := parsing.Bind(regexp.MustCompile(`(?P<key>\w+)=(?P<value>\d+)`), "x=787", &m)
+func Bind(re *regexp.Regexp, s string, dst any) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("parsing: Bind needs a pointer to a struct, not %T", dst))
+	}
+	v = v.Elem()
+	loc := re.FindStringSubmatchIndex(s)
+	if loc == nil {
+		return Errorf(1, "%q doesn't match %s", s, re)
+	}
+	groups := make(map[string]int)
+	for i, name := range re.SubexpNames() {
+		if name != "" {
+			groups[name] = i
+		}
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := f.Tag.Get("parse")
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		g, ok := groups[name]
+		if !ok {
+			if f.Tag.Get("parse") != "" {
+				panic(fmt.Sprintf("parsing: %s has no group %q for field %s", re, name, f.Name))
+			}
+			continue
+		}
+		start, end := loc[2*g], loc[2*g+1]
+		if start < 0 {
+			continue
+		}
+		if err := set(v.Field(i), s[start:end]); err != nil {
+			return Errorf(start+1, "%s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+func set(v reflect.Value, s string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a number that fits", s)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a number that fits", s)
+		}
+		v.SetUint(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", s)
+		}
+		v.SetBool(b)
+	default:
+		panic(fmt.Sprintf("parsing: can't bind to a field of type %s", v.Type()))
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+// Package parsing has the helpers the days use to pick their input apart.
+// Unlike the strconv calls with ignored errors they replace, they say what
+// was wrong and where, so a bad line in the input is reported instead of
+// turning into a zero and a wrong answer.
+//
+// The helpers work on one line at a time and report the column of the
+// problem; the caller, which knows which line it was, adds that with At:
+//
+//	for i, line := range lines {
+//		ns, err := parsing.Ints(line)
+//		if err != nil {
+//			return 0, parsing.At(i+1, err)
+//		}
+//		...
+//	}
+package parsing
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Error is a problem with the input. Line and Col count from 1; either may
+// be 0 if it isn't known.
+type Error struct {
+	Line int
+	Col  int
+	Err  error
+}
+
+func (e *Error) Error() string {
+	switch {
+	case e.Line > 0 && e.Col > 0:
+		return fmt.Sprintf("line %d, col %d: %v", e.Line, e.Col, e.Err)
+	case e.Line > 0:
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	case e.Col > 0:
+		return fmt.Sprintf("col %d: %v", e.Col, e.Err)
+	}
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Errorf returns an *Error at the given column, for the caller to add the
+// line to with At.
+func Errorf(col int, format string, args ...any) error {
+	return &Error{Col: col, Err: fmt.Errorf(format, args...)}
+}
+
+// At returns err as having happened on the given line. If err is already
+// an *Error its column is kept, and if it already has a line, that is taken
+// to be relative to line, as for a line inside a Block. A nil err stays nil.
+func At(line int, err error) error {
+	if err == nil {
+		return nil
+	}
+	var pe *Error
+	if errors.As(err, &pe) {
+		e := *pe
+		if e.Line > 0 {
+			e.Line += line - 1
+		} else {
+			e.Line = line
+		}
+		return &e
+	}
+	return &Error{Line: line, Err: err}
+}
+
+// Offset returns err with its column moved right by n, for a helper used on
+// the part of a line after the first n bytes.
+func Offset(n int, err error) error {
+	var pe *Error
+	if errors.As(err, &pe) && pe.Col > 0 {
+		e := *pe
+		e.Col += n
+		return &e
+	}
+	return err
+}
+
+// Int parses s as a decimal integer.
+func Int(s string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return 0, &Error{Col: 1, Err: fmt.Errorf("%q is not a number", s)}
+	}
+	return n, nil
+}
+
+var intpat = regexp.MustCompile(`-?\d+`)
+
+// Ints returns every integer in s, ignoring whatever separates them. A minus
+// sign directly before the digits makes a number negative.
+func Ints(s string) ([]int, error) {
+	var ns []int
+	for _, loc := range intpat.FindAllStringIndex(s, -1) {
+		n, err := strconv.Atoi(s[loc[0]:loc[1]])
+		if err != nil {
+			return nil, Errorf(loc[0]+1, "%s is out of range", s[loc[0]:loc[1]])
+		}
+		ns = append(ns, n)
+	}
+	return ns, nil
+}
+
+// Fields splits s around runs of whitespace, as strings.Fields does, and
+// checks that there are n of them. A negative n accepts any number.
+func Fields(s string, n int) ([]string, error) {
+	fs := strings.Fields(s)
+	if n >= 0 && len(fs) != n {
+		return nil, Errorf(1, "want %d fields, found %d in %q", n, len(fs), s)
+	}
+	return fs, nil
+}
+
+// IntFields splits s around runs of whitespace and parses each field as an
+// integer, checking that there are n of them. A negative n accepts any
+// number. Unlike Ints, anything that isn't a number is an error.
+func IntFields(s string, n int) ([]int, error) {
+	var ns []int
+	for i := 0; i < len(s); {
+		if s[i] == ' ' || s[i] == '\t' {
+			i++
+			continue
+		}
+		j := strings.IndexAny(s[i:], " \t")
+		if j < 0 {
+			j = len(s)
+		} else {
+			j += i
+		}
+		v, err := strconv.Atoi(s[i:j])
+		if err != nil {
+			return nil, Errorf(i+1, "%q is not a number", s[i:j])
+		}
+		ns = append(ns, v)
+		i = j
+	}
+	if n >= 0 && len(ns) != n {
+		return nil, Errorf(1, "want %d numbers, found %d in %q", n, len(ns), s)
+	}
+	return ns, nil
+}
+
+// KeyValue splits s at the first sep into a key and value, both trimmed of
+// spaces.
+func KeyValue(s, sep string) (key, value string, err error) {
+	k, v, ok := strings.Cut(s, sep)
+	if !ok {
+		return "", "", Errorf(1, "no %q in %q", sep, s)
+	}
+	return strings.TrimSpace(k), strings.TrimSpace(v), nil
+}
+
+// Block is a group of lines from the input, separated from the others by
+// blank lines. Start is the line number of its first line.
+type Block struct {
+	Start int
+	Lines []string
+}
+
+// At is At for a line within the block, counting from 0.
+func (b Block) At(i int, err error) error {
+	return At(b.Start+i, err)
+}
+
+// Blocks splits lines into the groups separated by blank lines. Runs of
+// blank lines, and blank lines at either end, don't make empty blocks.
+func Blocks(lines []string) []Block {
+	var blocks []Block
+	var cur *Block
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			cur = nil
+			continue
+		}
+		if cur == nil {
+			blocks = append(blocks, Block{Start: i + 1})
+			cur = &blocks[len(blocks)-1]
+		}
+		cur.Lines = append(cur.Lines, line)
+	}
+	return blocks
+}
@@ -0,0 +1,223 @@
+package parsing
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func Test_Error(t *testing.T) {
+	base := errors.New("bad")
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"plain", &Error{Err: base}, "bad"},
+		{"line", &Error{Line: 3, Err: base}, "line 3: bad"},
+		{"col", &Error{Col: 7, Err: base}, "col 7: bad"},
+		{"both", &Error{Line: 3, Col: 7, Err: base}, "line 3, col 7: bad"},
+		{"at", At(4, base), "line 4: bad"},
+		{"at col", At(4, Errorf(2, "bad")), "line 4, col 2: bad"},
+		{"at block", At(10, At(3, base)), "line 12: bad"},
+		{"offset", Offset(5, Errorf(2, "bad")), "col 7: bad"},
+		{"offset no col", Offset(5, At(1, base)), "line 1: bad"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.want {
+				t.Errorf("Error() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if !errors.Is(At(2, Offset(3, &Error{Col: 1, Err: base})), base) {
+		t.Error("At and Offset should keep the underlying error")
+	}
+	if At(1, nil) != nil || Offset(1, nil) != nil {
+		t.Error("At and Offset should leave nil alone")
+	}
+}
+
+func Test_Ints(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    []int
+		wantErr string
+	}{
+		{"", nil, ""},
+		{"seeds: 79 14 55 13", []int{79, 14, 55, 13}, ""},
+		{"x=-3,y=4", []int{-3, 4}, ""},
+		{"Card  12: 41 48", []int{12, 41, 48}, ""},
+		{"1 99999999999999999999", nil, "col 3: 99999999999999999999 is out of range"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := Ints(tt.s)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Ints() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Ints() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_IntFields(t *testing.T) {
+	tests := []struct {
+		s       string
+		n       int
+		want    []int
+		wantErr string
+	}{
+		{"0 3 6 9 12 15", -1, []int{0, 3, 6, 9, 12, 15}, ""},
+		{"  50\t98 2 ", 3, []int{50, 98, 2}, ""},
+		{"-4 -2", 2, []int{-4, -2}, ""},
+		{"1 2 x3", 3, nil, `col 5: "x3" is not a number`},
+		{"1 2", 3, nil, `col 1: want 3 numbers, found 2 in "1 2"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := IntFields(tt.s, tt.n)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("IntFields() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("IntFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Int(t *testing.T) {
+	if n, err := Int(" 42 "); n != 42 || err != nil {
+		t.Errorf("Int() = %d, %v, want 42", n, err)
+	}
+	if _, err := Int("4 2"); err == nil {
+		t.Error("Int() should reject two numbers")
+	}
+}
+
+func Test_Fields(t *testing.T) {
+	got, err := Fields("32T3K 765", 2)
+	if err != nil || !reflect.DeepEqual(got, []string{"32T3K", "765"}) {
+		t.Errorf("Fields() = %v, %v", got, err)
+	}
+	if _, err := Fields("32T3K", 2); err == nil {
+		t.Error("Fields() with too few fields should fail")
+	}
+	if got, _ := Fields("a b c", -1); len(got) != 3 {
+		t.Errorf("Fields() = %v, want 3 fields", got)
+	}
+}
+
+func Test_KeyValue(t *testing.T) {
+	tests := []struct {
+		s, sep     string
+		key, value string
+		ok         bool
+	}{
+		{"Time:      7  15   30", ":", "Time", "7  15   30", true},
+		{"broadcaster -> a, b, c", "->", "broadcaster", "a, b, c", true},
+		{"x=787", "=", "x", "787", true},
+		{"a=b=c", "=", "a", "b=c", true},
+		{"nothing here", ":", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			key, value, err := KeyValue(tt.s, tt.sep)
+			if (err == nil) != tt.ok {
+				t.Fatalf("KeyValue() error = %v, want ok %v", err, tt.ok)
+			}
+			if key != tt.key || value != tt.value {
+				t.Errorf("KeyValue() = %q, %q, want %q, %q", key, value, tt.key, tt.value)
+			}
+		})
+	}
+}
+
+func Test_Blocks(t *testing.T) {
+	lines := []string{"", "a", "b", "", "", "c", "  ", "d", "e", ""}
+	want := []Block{
+		{Start: 2, Lines: []string{"a", "b"}},
+		{Start: 6, Lines: []string{"c"}},
+		{Start: 8, Lines: []string{"d", "e"}},
+	}
+	got := Blocks(lines)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Blocks() = %v, want %v", got, want)
+	}
+	if err := got[2].At(1, Errorf(3, "bad")); err.Error() != "line 9, col 3: bad" {
+		t.Errorf("Block.At() = %q", err)
+	}
+	if got := Blocks(nil); len(got) != 0 {
+		t.Errorf("Blocks(nil) = %v, want none", got)
+	}
+}
+
+func Test_Bind(t *testing.T) {
+	type instruction struct {
+		Op     string `parse:"dir"`
+		Steps  int
+		Color  string
+		Hidden bool
+		secret string
+	}
+	re := regexp.MustCompile(`^(?P<dir>[RDLU]) (?P<steps>\d+)(?: \((?P<color>#[0-9a-f]{6})\))?$`)
+	tests := []struct {
+		s       string
+		want    instruction
+		wantErr string
+	}{
+		{"R 6 (#70c710)", instruction{Op: "R", Steps: 6, Color: "#70c710"}, ""},
+		{"U 12", instruction{Op: "U", Steps: 12}, ""},
+		{"X 6", instruction{}, `col 1: "X 6" doesn't match ` + re.String()},
+		{"D 99999999999999999999", instruction{}, `col 3: steps: "99999999999999999999" is not a number that fits`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			var got instruction
+			err := Bind(re, tt.s, &got)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Bind() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Bind() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	var small struct {
+		N    uint8
+		Flag bool
+	}
+	re = regexp.MustCompile(`(?P<n>\d+) (?P<flag>\w+)`)
+	if err := Bind(re, "7 true", &small); err != nil || small.N != 7 || !small.Flag {
+		t.Errorf("Bind() = %+v, %v", small, err)
+	}
+	if err := Bind(re, "300 true", &small); err == nil {
+		t.Error("Bind() should reject a number too big for the field")
+	}
+	if err := Bind(re, "7 maybe", &small); err == nil {
+		t.Error("Bind() should reject a bool that isn't one")
+	}
+}
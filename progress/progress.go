@@ -0,0 +1,255 @@
+// Package progress replaces the Printf every million presses that the long
+// simulations used to do. A solver starts a Counter from its context,
+//
+//	presses := progress.Start(ctx, "presses", 0)
+//	defer presses.Done()
+//
+// and adds to it as it goes. If the context came from a Monitor, the
+// monitor samples each running counter every so often and hands a Status,
+// with its rate and, when the total is known, its ETA, to a Reporter: Line
+// for a live status line on a terminal, or Log for slog records. Otherwise
+// the counter costs an atomic add and nothing is shown.
+package progress
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Status is a snapshot of a Counter.
+type Status struct {
+	Name    string
+	Done    int64
+	Total   int64 // 0 if not known
+	Elapsed time.Duration
+}
+
+// Rate returns the iterations per second so far.
+func (s Status) Rate() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Done) / s.Elapsed.Seconds()
+}
+
+// ETA returns how much longer the counter should take at its rate so far.
+// It reports false if the total isn't known or nothing has been done yet.
+func (s Status) ETA() (time.Duration, bool) {
+	rate := s.Rate()
+	if s.Total <= 0 || rate <= 0 {
+		return 0, false
+	}
+	left := float64(s.Total-s.Done) / rate
+	if left < 0 {
+		left = 0
+	}
+	return time.Duration(left * float64(time.Second)), true
+}
+
+// String returns the status as it shows on the status line, such as
+// "day20 part 2: presses 1.2M (410k/s)" or
+// "day12 part 2: rows 250/1000 25% (50/s, ETA 15s)".
+func (s Status) String() string {
+	if s.Total <= 0 {
+		return fmt.Sprintf("%s %s (%s/s)", s.Name, count(float64(s.Done)), count(s.Rate()))
+	}
+	pct := 100 * float64(s.Done) / float64(s.Total)
+	msg := fmt.Sprintf("%s %d/%d %.0f%% (%s/s", s.Name, s.Done, s.Total, pct, count(s.Rate()))
+	if eta, ok := s.ETA(); ok {
+		msg += ", ETA " + eta.Round(time.Second).String()
+	}
+	return msg + ")"
+}
+
+// count formats n with a k, M or G suffix once it gets big.
+func count(n float64) string {
+	switch {
+	case n >= 1e9:
+		return fmt.Sprintf("%.3gG", n/1e9)
+	case n >= 1e6:
+		return fmt.Sprintf("%.3gM", n/1e6)
+	case n >= 1e3:
+		return fmt.Sprintf("%.3gk", n/1e3)
+	}
+	return fmt.Sprintf("%.3g", n)
+}
+
+// A Reporter shows the statuses a Monitor samples. Report is called every
+// interval for each running counter, and Done once a counter that has been
+// reported finishes. The Monitor never calls them concurrently.
+type Reporter interface {
+	Report(Status)
+	Done(Status)
+}
+
+// A Counter counts one loop's iterations.
+type Counter struct {
+	name     string
+	start    time.Time
+	done     atomic.Int64
+	total    atomic.Int64
+	m        *Monitor
+	reported bool // guarded by m.mu
+}
+
+// Add records n more iterations.
+func (c *Counter) Add(n int) {
+	c.done.Add(int64(n))
+}
+
+// Set records that n iterations have been done in all.
+func (c *Counter) Set(n int) {
+	c.done.Store(int64(n))
+}
+
+// SetTotal sets the number of iterations expected, for when it only becomes
+// known part way through.
+func (c *Counter) SetTotal(n int) {
+	c.total.Store(int64(n))
+}
+
+// Status returns a snapshot of the counter.
+func (c *Counter) Status() Status {
+	return Status{
+		Name:    c.name,
+		Done:    c.done.Load(),
+		Total:   c.total.Load(),
+		Elapsed: time.Since(c.start),
+	}
+}
+
+// Done stops the counter being reported.
+func (c *Counter) Done() {
+	if c.m != nil {
+		c.m.remove(c)
+	}
+}
+
+type key int
+
+const (
+	monitorKey key = iota
+	prefixKey
+	writerKey
+)
+
+// WithWriter returns a context whose solvers write anything they have to
+// say for themselves to w, such as a Line's Writer, which keeps it clear of
+// the status line.
+func WithWriter(ctx context.Context, w io.Writer) context.Context {
+	return context.WithValue(ctx, writerKey, w)
+}
+
+// Writer returns where ctx says a solver's own output should go, or stderr.
+func Writer(ctx context.Context) io.Writer {
+	if w, ok := ctx.Value(writerKey).(io.Writer); ok {
+		return w
+	}
+	return os.Stderr
+}
+
+// WithPrefix returns a context whose counters have prefix in front of their
+// names, such as "day20 part 2".
+func WithPrefix(ctx context.Context, prefix string) context.Context {
+	return context.WithValue(ctx, prefixKey, prefix)
+}
+
+// Start returns a new counter called name expecting total iterations, or 0
+// if the total isn't known. It is reported if ctx came from a Monitor.
+func Start(ctx context.Context, name string, total int) *Counter {
+	if prefix, ok := ctx.Value(prefixKey).(string); ok && prefix != "" {
+		name = prefix + ": " + name
+	}
+	c := &Counter{name: name, start: time.Now()}
+	c.total.Store(int64(total))
+	if m, ok := ctx.Value(monitorKey).(*Monitor); ok {
+		c.m = m
+		m.add(c)
+	}
+	return c
+}
+
+// A Monitor samples running counters and passes their statuses to a
+// Reporter.
+type Monitor struct {
+	r        Reporter
+	mu       sync.Mutex
+	counters []*Counter
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewMonitor returns a Monitor that reports to r every interval, and starts
+// it running. A counter that finishes within the first interval is never
+// reported at all.
+func NewMonitor(r Reporter, interval time.Duration) *Monitor {
+	m := &Monitor{r: r, stop: make(chan struct{})}
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		tick := time.NewTicker(interval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-tick.C:
+				m.report()
+			case <-m.stop:
+				return
+			}
+		}
+	}()
+	return m
+}
+
+// Context returns a copy of ctx whose counters are reported by m.
+func (m *Monitor) Context(ctx context.Context) context.Context {
+	return context.WithValue(ctx, monitorKey, m)
+}
+
+// Close stops the monitor, finishing any counters still running.
+func (m *Monitor) Close() {
+	close(m.stop)
+	m.wg.Wait()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, c := range m.counters {
+		if c.reported {
+			m.r.Done(c.Status())
+		}
+	}
+	m.counters = nil
+}
+
+func (m *Monitor) report() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, c := range m.counters {
+		c.reported = true
+		m.r.Report(c.Status())
+	}
+}
+
+func (m *Monitor) add(c *Counter) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counters = append(m.counters, c)
+}
+
+func (m *Monitor) remove(c *Counter) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := slices.Index(m.counters, c)
+	if i < 0 {
+		return
+	}
+	m.counters = slices.Delete(m.counters, i, i+1)
+	if c.reported {
+		m.r.Done(c.Status())
+	}
+}
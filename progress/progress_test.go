@@ -0,0 +1,161 @@
+package progress
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_Status(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       Status
+		rate    float64
+		eta     time.Duration
+		etaOK   bool
+		display string
+	}{
+		{"nothing yet", Status{Name: "presses"}, 0, 0, false, "presses 0 (0/s)"},
+		{"open ended", Status{Name: "presses", Done: 1_200_000, Elapsed: 3 * time.Second}, 400_000, 0, false, "presses 1.2M (400k/s)"},
+		{"known total", Status{Name: "rows", Done: 250, Total: 1000, Elapsed: 5 * time.Second}, 50, 15 * time.Second, true, "rows 250/1000 25% (50/s, ETA 15s)"},
+		{"past the total", Status{Name: "rows", Done: 12, Total: 10, Elapsed: time.Second}, 12, 0, true, "rows 12/10 120% (12/s, ETA 0s)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.Rate(); got != tt.rate {
+				t.Errorf("Rate() = %v, want %v", got, tt.rate)
+			}
+			eta, ok := tt.s.ETA()
+			if eta != tt.eta || ok != tt.etaOK {
+				t.Errorf("ETA() = %v, %v, want %v, %v", eta, ok, tt.eta, tt.etaOK)
+			}
+			if got := tt.s.String(); got != tt.display {
+				t.Errorf("String() = %q, want %q", got, tt.display)
+			}
+		})
+	}
+}
+
+// recorder is a Reporter that remembers what it was given.
+type recorder struct {
+	mu       sync.Mutex
+	reported []Status
+	done     []Status
+}
+
+func (r *recorder) Report(s Status) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reported = append(r.reported, s)
+}
+
+func (r *recorder) Done(s Status) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.done = append(r.done, s)
+}
+
+func Test_Monitor(t *testing.T) {
+	r := &recorder{}
+	m := NewMonitor(r, time.Millisecond)
+	ctx := WithPrefix(m.Context(context.Background()), "day20 part 2")
+
+	quick := Start(ctx, "quick", 0)
+	quick.Done()
+
+	slow := Start(ctx, "presses", 100)
+	slow.Add(40)
+	for {
+		r.mu.Lock()
+		n := len(r.reported)
+		r.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	slow.Set(100)
+	slow.Done()
+	slow.Done()
+	m.Close()
+
+	if len(r.done) != 1 {
+		t.Fatalf("Done() called with %v, want one status", r.done)
+	}
+	if got := r.done[0]; got.Name != "day20 part 2: presses" || got.Done != 100 || got.Total != 100 {
+		t.Errorf("Done() got %+v", got)
+	}
+	for _, s := range r.reported {
+		if s.Name != "day20 part 2: presses" {
+			t.Errorf("Report() got %q, which had already finished or wasn't prefixed", s.Name)
+		}
+	}
+
+	// without a monitor, a counter still counts
+	c := Start(context.Background(), "spins", 0)
+	c.Add(3)
+	c.Done()
+	if s := c.Status(); s.Name != "spins" || s.Done != 3 {
+		t.Errorf("Status() = %+v", s)
+	}
+}
+
+func Test_Writer(t *testing.T) {
+	if w := Writer(context.Background()); w != os.Stderr {
+		t.Errorf("Writer() = %v, want stderr", w)
+	}
+	var buf bytes.Buffer
+	if w := Writer(WithWriter(context.Background(), &buf)); w != &buf {
+		t.Errorf("Writer() = %v, want the one given", w)
+	}
+}
+
+func Test_Line(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLine(&buf)
+	l.Report(Status{Name: "a", Done: 1, Elapsed: time.Second})
+	l.Report(Status{Name: "b", Done: 2, Elapsed: time.Second})
+	l.Report(Status{Name: "a", Done: 3, Elapsed: time.Second})
+	w := l.Writer()
+	w.Write([]byte("INFO hello\n"))
+	l.Done(Status{Name: "a"})
+	l.Done(Status{Name: "b"})
+	w.Write([]byte("INFO bye\n"))
+	want := clearLine + "a 1 (1/s)" +
+		clearLine + "a 1 (1/s) | b 2 (2/s)" +
+		clearLine + "a 3 (3/s) | b 2 (2/s)" +
+		clearLine + "INFO hello\n" + clearLine + "a 3 (3/s) | b 2 (2/s)" +
+		clearLine + "b 2 (2/s)" +
+		clearLine +
+		"INFO bye\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q\nwant %q", got, want)
+	}
+}
+
+func Test_Log(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	l := NewLog(logger)
+	l.Report(Status{Name: "rows", Done: 250, Total: 1000, Elapsed: 5 * time.Second})
+	l.Done(Status{Name: "presses", Done: 7, Elapsed: 2 * time.Second})
+	want := []string{
+		`level=INFO msg=progress task=rows done=250 total=1000 elapsed=5s rate=50 eta=15s`,
+		`level=INFO msg=finished task=presses done=7 elapsed=2s rate=4`,
+	}
+	if got := strings.Split(strings.TrimSpace(buf.String()), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q\nwant %q", got, want)
+	}
+}
@@ -0,0 +1,131 @@
+package progress
+
+import (
+	"io"
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// clearLine returns the cursor to the start of the line and erases it.
+const clearLine = "\r\x1b[K"
+
+// Line is a Reporter that keeps a single status line up to date on a
+// terminal, with the running counters side by side.
+type Line struct {
+	w        io.Writer
+	mu       sync.Mutex
+	names    []string
+	statuses map[string]string
+	shown    bool
+}
+
+// NewLine returns a Line writing to w.
+func NewLine(w io.Writer) *Line {
+	return &Line{w: w, statuses: make(map[string]string)}
+}
+
+// Report implements Reporter.
+func (l *Line) Report(s Status) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.statuses[s.Name]; !ok {
+		l.names = append(l.names, s.Name)
+	}
+	l.statuses[s.Name] = s.String()
+	l.draw()
+}
+
+// Done implements Reporter.
+func (l *Line) Done(s Status) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if i := slices.Index(l.names, s.Name); i >= 0 {
+		l.names = slices.Delete(l.names, i, i+1)
+	}
+	delete(l.statuses, s.Name)
+	l.draw()
+}
+
+// draw rewrites the line. l.mu must be held.
+func (l *Line) draw() {
+	if len(l.names) == 0 {
+		if l.shown {
+			io.WriteString(l.w, clearLine)
+			l.shown = false
+		}
+		return
+	}
+	parts := make([]string, len(l.names))
+	for i, name := range l.names {
+		parts[i] = l.statuses[name]
+	}
+	io.WriteString(l.w, clearLine+strings.Join(parts, " | "))
+	l.shown = true
+}
+
+// Writer returns a writer to the same place as l that takes the status
+// line out of the way of whatever is written through it and puts it back
+// afterwards, so that log output doesn't end up tacked on to the end of it.
+func (l *Line) Writer() io.Writer {
+	return lineWriter{l}
+}
+
+type lineWriter struct {
+	l *Line
+}
+
+func (w lineWriter) Write(p []byte) (int, error) {
+	w.l.mu.Lock()
+	defer w.l.mu.Unlock()
+	if w.l.shown {
+		io.WriteString(w.l.w, clearLine)
+		w.l.shown = false
+	}
+	n, err := w.l.w.Write(p)
+	if err == nil && len(p) > 0 && p[len(p)-1] == '\n' {
+		w.l.draw()
+	}
+	return n, err
+}
+
+// Log is a Reporter that logs each status as an info record, for when
+// nobody is watching a terminal.
+type Log struct {
+	logger *slog.Logger
+}
+
+// NewLog returns a Log writing to logger, or to the default logger if it's
+// nil.
+func NewLog(logger *slog.Logger) *Log {
+	return &Log{logger: logger}
+}
+
+func (l *Log) log(msg string, s Status, final bool) {
+	logger := l.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	args := []any{"task", s.Name, "done", s.Done}
+	if s.Total > 0 {
+		args = append(args, "total", s.Total)
+	}
+	args = append(args, "elapsed", s.Elapsed.Round(time.Millisecond), "rate", math.Round(s.Rate()))
+	if eta, ok := s.ETA(); ok && !final {
+		args = append(args, "eta", eta.Round(time.Second))
+	}
+	logger.Info(msg, args...)
+}
+
+// Report implements Reporter.
+func (l *Log) Report(s Status) {
+	l.log("progress", s, false)
+}
+
+// Done implements Reporter.
+func (l *Log) Done(s Status) {
+	l.log("finished", s, true)
+}
@@ -0,0 +1,105 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// IsTerminal reports whether f looks like a terminal rather than a file or
+// a pipe, for output that is only worth coloring or redrawing on a screen.
+func IsTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// LogHandler is a slog.Handler for the solvers' debug output. It writes one
+// line per record, "LEVEL message key=value ...", with anything below debug
+// shown as TRACE. It leaves out the timestamp, since nobody cares when a hand
+// of cards was ranked. String values that span several lines, such as a
+// picture of a grid, are written out in full below the record instead of
+// being quoted.
+type LogHandler struct {
+	w     io.Writer
+	mu    *sync.Mutex
+	level slog.Leveler
+	attrs []slog.Attr
+	group string
+}
+
+// NewLogHandler returns a LogHandler writing records at or above level to w.
+func NewLogHandler(w io.Writer, level slog.Leveler) *LogHandler {
+	return &LogHandler{w: w, mu: &sync.Mutex{}, level: level}
+}
+
+// Enabled implements slog.Handler.
+func (h *LogHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= h.level.Level()
+}
+
+// Handle implements slog.Handler.
+func (h *LogHandler) Handle(_ context.Context, r slog.Record) error {
+	var line strings.Builder
+	var blocks []string
+	if r.Level < slog.LevelDebug {
+		line.WriteString("TRACE")
+	} else {
+		line.WriteString(r.Level.String())
+	}
+	line.WriteByte(' ')
+	line.WriteString(r.Message)
+	add := func(a slog.Attr) bool {
+		v := a.Value.Resolve().String()
+		if strings.Contains(v, "\n") {
+			blocks = append(blocks, strings.TrimRight(v, "\n"))
+			return true
+		}
+		fmt.Fprintf(&line, " %s=%s", a.Key, v)
+		return true
+	}
+	for _, a := range h.attrs {
+		add(a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		if h.group != "" {
+			a.Key = h.group + "." + a.Key
+		}
+		return add(a)
+	})
+	line.WriteByte('\n')
+	for _, b := range blocks {
+		line.WriteString(b)
+		line.WriteByte('\n')
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, line.String())
+	return err
+}
+
+// WithAttrs implements slog.Handler.
+func (h *LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, a := range attrs {
+		if h.group != "" {
+			a.Key = h.group + "." + a.Key
+		}
+		h2.attrs = append(h2.attrs, a)
+	}
+	return &h2
+}
+
+// WithGroup implements slog.Handler.
+func (h *LogHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	if h2.group != "" {
+		name = h2.group + "." + name
+	}
+	h2.group = name
+	return &h2
+}
@@ -0,0 +1,72 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Params are settings for a day's solvers beyond its input, such as the
+// vocabulary day01 reads digits in. The aoc command takes them as
+// -param key=value, and Run passes them to the solvers in their context.
+type Params map[string]string
+
+// String implements flag.Value.
+func (p Params) String() string {
+	keys := make([]string, 0, len(p))
+	for k := range p {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for i, k := range keys {
+		keys[i] = k + "=" + p[k]
+	}
+	return strings.Join(keys, ",")
+}
+
+// Set implements flag.Value, adding a key=value pair.
+func (p *Params) Set(s string) error {
+	k, v, ok := strings.Cut(s, "=")
+	if !ok || k == "" {
+		return fmt.Errorf("%q should be key=value", s)
+	}
+	if *p == nil {
+		*p = make(Params)
+	}
+	(*p)[k] = v
+	return nil
+}
+
+type paramsKey struct{}
+
+// WithParams returns a copy of ctx carrying p.
+func WithParams(ctx context.Context, p Params) context.Context {
+	return context.WithValue(ctx, paramsKey{}, p)
+}
+
+// Param returns the value of the named parameter from ctx, and whether it
+// was given at all.
+func Param(ctx context.Context, key string) (string, bool) {
+	p, _ := ctx.Value(paramsKey{}).(Params)
+	v, ok := p[key]
+	return v, ok
+}
+
+// CheckParams returns an error if p has a parameter that none of days
+// takes, which is most likely a typo.
+func CheckParams(days []Day, p Params) error {
+	for k := range p {
+		known := false
+		for _, d := range days {
+			if _, ok := d.Params[k]; ok {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("no day here takes a %q parameter", k)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package runner
+
+import (
+	"fmt"
+	"sort"
+)
+
+var registry = make(map[int]Day)
+
+// Register makes a day's solvers available to Lookup and Days. It is meant
+// to be called from the init function of each day's package, and panics if
+// the day is registered twice.
+func Register(d Day) {
+	if _, ok := registry[d.Number]; ok {
+		panic(fmt.Sprintf("runner: day %d registered twice", d.Number))
+	}
+	registry[d.Number] = d
+}
+
+// Lookup returns the solvers registered for day n.
+func Lookup(n int) (Day, bool) {
+	d, ok := registry[n]
+	return d, ok
+}
+
+// Days returns every registered day in order.
+func Days() []Day {
+	days := make([]Day, 0, len(registry))
+	for _, d := range registry {
+		days = append(days, d)
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Number < days[j].Number
+	})
+	return days
+}
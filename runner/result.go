@@ -0,0 +1,88 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Result records the answer to one part of one day.
+type Result struct {
+	Day      int           `json:"day"`
+	Part     int           `json:"part"`
+	Dataset  string        `json:"dataset"`
+	Answer   int           `json:"answer"`
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// A ResultWriter writes Results as they are produced. Close must be called
+// after the last one, since some formats can't be written until then.
+type ResultWriter interface {
+	Write(r Result) error
+	Close() error
+}
+
+// NewResultWriter returns a ResultWriter for format, which is one of
+// "text", "json" (a single array, written on Close) or "ndjson" (one object
+// per line, written as each part finishes). For text, timed adds how long
+// each part took; the other formats always include it.
+func NewResultWriter(w io.Writer, format string, timed bool) (ResultWriter, error) {
+	switch format {
+	case "text":
+		return &textWriter{w: w, timed: timed}, nil
+	case "json":
+		return &jsonWriter{w: w, results: []Result{}}, nil
+	case "ndjson":
+		return &ndjsonWriter{enc: json.NewEncoder(w)}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+type textWriter struct {
+	w     io.Writer
+	timed bool
+}
+
+func (t *textWriter) Write(r Result) error {
+	name := Day{Number: r.Day}.Name()
+	var err error
+	if t.timed {
+		_, err = fmt.Fprintf(t.w, "%s part %d: %d (%v)\n", name, r.Part, r.Answer, r.Duration)
+	} else {
+		_, err = fmt.Fprintf(t.w, "%s part %d: %d\n", name, r.Part, r.Answer)
+	}
+	return err
+}
+
+func (t *textWriter) Close() error {
+	return nil
+}
+
+type jsonWriter struct {
+	w       io.Writer
+	results []Result
+}
+
+func (j *jsonWriter) Write(r Result) error {
+	j.results = append(j.results, r)
+	return nil
+}
+
+func (j *jsonWriter) Close() error {
+	enc := json.NewEncoder(j.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(j.results)
+}
+
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonWriter) Write(r Result) error {
+	return n.enc.Encode(r)
+}
+
+func (n *ndjsonWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,240 @@
+// Package runner holds the boilerplate that every day used to copy into its
+// main(): picking a dataset, reading it, splitting it into lines and running
+// the two parts of the puzzle. Each day registers itself with Register so the
+// aoc command can find it.
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kentquirk/aoc2023/progress"
+)
+
+// PartFunc solves one part of a puzzle given the lines of the input. It
+// returns an error, rather than a wrong answer, if the input doesn't make
+// sense. Solvers that can run for a long time check ctx in their loops and
+// give up with its error once it's done.
+type PartFunc func(ctx context.Context, lines []string) (int, error)
+
+// Day describes the solvers for a single day's puzzle.
+type Day struct {
+	Number int
+	Part1  PartFunc
+	Part2  PartFunc
+	// Default is the dataset used when none is given; if empty, "sample" is used.
+	Default string
+	// Params describes the parameters the solvers take, if any, by name.
+	Params map[string]string
+}
+
+// Name returns the name of the day's directory, such as "day07".
+func (d Day) Name() string {
+	return fmt.Sprintf("day%02d", d.Number)
+}
+
+// Dataset returns the default dataset for the day.
+func (d Day) Dataset() string {
+	if d.Default == "" {
+		return "sample"
+	}
+	return d.Default
+}
+
+// Part returns the solver for part n (1 or 2), or nil if there isn't one.
+func (d Day) Part(n int) PartFunc {
+	switch n {
+	case 1:
+		return d.Part1
+	case 2:
+		return d.Part2
+	}
+	return nil
+}
+
+// Options controls a single run of a day's solvers.
+type Options struct {
+	// Dataset says where the input comes from, as for Open. If empty, the
+	// day's default is used.
+	Dataset string
+	// Part is 1 or 2 to run just that part, or 0 to run both.
+	Part int
+	// Stdin reads the input from standard input, as a Dataset of "-" does.
+	Stdin bool
+	// Root is the directory holding the dayNN directories.
+	Root string
+	// Timeout is how long each part may take; 0 means no limit.
+	Timeout time.Duration
+	// Params are passed to the solvers through their context; see Param.
+	Params Params
+}
+
+// Lines splits the contents of an input file into lines. Line endings may
+// be \n or \r\n, and blank lines at the end are dropped, so an input means
+// the same whether or not it ends with a newline.
+func Lines(b []byte) []string {
+	lines := strings.Split(string(b), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Read reads all of r and splits it into lines as Lines does.
+func Read(r io.Reader) ([]string, error) {
+	var lines []string
+	s := NewScanner(r)
+	for s.Scan() {
+		lines = append(lines, s.Text())
+	}
+	return lines, s.Err()
+}
+
+// DataPath returns the path of the named dataset for d below root.
+func DataPath(root string, d Day, name string) string {
+	return filepath.Join(root, d.Name(), "data", name+".txt")
+}
+
+// Load reads the named dataset for d from below root.
+func Load(root string, d Day, name string) ([]string, error) {
+	return readFile(DataPath(root, d, name))
+}
+
+func readFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// StdinDataset is the dataset that Open reads from standard input.
+const StdinDataset = "-"
+
+// IsPath reports whether Open takes dataset to be the path of a file rather
+// than the name of one in the day's data directory: that is, if it has a
+// directory or an extension in it, as in "./gen.txt" or "/tmp/big".
+func IsPath(dataset string) bool {
+	return strings.ContainsRune(dataset, '/') || strings.ContainsRune(dataset, filepath.Separator) ||
+		filepath.Ext(dataset) != ""
+}
+
+// Open reads the input for d that dataset describes: "-" for standard
+// input, the path of a file (see IsPath), or else the name of a dataset in
+// the day's data directory below root. An empty dataset is the day's
+// default. It also returns the name to report the input by.
+func Open(root string, d Day, dataset string) (string, []string, error) {
+	if dataset == "" {
+		dataset = d.Dataset()
+	}
+	switch {
+	case dataset == StdinDataset:
+		lines, err := Read(os.Stdin)
+		return "stdin", lines, err
+	case IsPath(dataset):
+		lines, err := readFile(dataset)
+		return dataset, lines, err
+	}
+	lines, err := Load(root, d, dataset)
+	return dataset, lines, err
+}
+
+// PartError is the error a solver returned, with which day, part and
+// dataset it was solving. Errors about the input itself are usually a
+// *parsing.Error inside it, saying where in the input the problem is.
+type PartError struct {
+	Day     int
+	Part    int
+	Dataset string
+	Err     error
+}
+
+func (e *PartError) Error() string {
+	return fmt.Sprintf("day%02d part %d: %s: %v", e.Day, e.Part, e.Dataset, e.Err)
+}
+
+func (e *PartError) Unwrap() error {
+	return e.Err
+}
+
+// Solve calls f, giving up once ctx is done or, if timeout is positive, once
+// it has run for that long. A solver that doesn't check its context is left
+// to finish in the background; the process is expected to exit soon after.
+func Solve(ctx context.Context, f PartFunc, lines []string, timeout time.Duration) (int, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		cause := fmt.Errorf("gave up after %v: %w", timeout, context.DeadlineExceeded)
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, cause)
+		defer cancel()
+	}
+	type result struct {
+		answer int
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		answer, err := f(ctx, lines)
+		done <- result{answer, err}
+	}()
+	select {
+	case r := <-done:
+		if r.err != nil && ctx.Err() != nil && errors.Is(r.err, ctx.Err()) {
+			r.err = context.Cause(ctx)
+		}
+		return r.answer, r.err
+	case <-ctx.Done():
+		return 0, context.Cause(ctx)
+	}
+}
+
+// Run loads the input described by opts and writes a Result for each
+// requested part to rw.
+func Run(ctx context.Context, rw ResultWriter, d Day, opts Options) error {
+	if opts.Part < 0 || opts.Part > 2 {
+		return fmt.Errorf("no such part %d", opts.Part)
+	}
+	dataset := opts.Dataset
+	if opts.Stdin {
+		dataset = StdinDataset
+	}
+	name, lines, err := Open(opts.Root, d, dataset)
+	if err != nil {
+		return err
+	}
+	for n := 1; n <= 2; n++ {
+		if opts.Part != 0 && opts.Part != n {
+			continue
+		}
+		f := d.Part(n)
+		if f == nil {
+			continue
+		}
+		start := time.Now()
+		pctx := progress.WithPrefix(WithParams(ctx, opts.Params), fmt.Sprintf("%s part %d", d.Name(), n))
+		answer, err := Solve(pctx, f, lines, opts.Timeout)
+		if err != nil {
+			return &PartError{Day: d.Number, Part: n, Dataset: name, Err: err}
+		}
+		r := Result{
+			Day:      d.Number,
+			Part:     n,
+			Dataset:  name,
+			Answer:   answer,
+			Duration: time.Since(start),
+		}
+		if err := rw.Write(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
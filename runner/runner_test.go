@@ -0,0 +1,417 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+func Test_Run(t *testing.T) {
+	root := t.TempDir()
+	d := Day{
+		Number: 1,
+		Part1:  func(ctx context.Context, lines []string) (int, error) { return len(lines), nil },
+		Part2:  func(ctx context.Context, lines []string) (int, error) { return len(lines[2]), nil },
+	}
+	if err := os.MkdirAll(filepath.Join(root, "day01", "data"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(DataPath(root, d, "sample"), []byte("a\nbb\nccc"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		part int
+		want string
+	}{
+		{"both", 0, "day01 part 1: 3\nday01 part 2: 3\n"},
+		{"part1", 1, "day01 part 1: 3\n"},
+		{"part2", 2, "day01 part 2: 3\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			rw, _ := NewResultWriter(&buf, "text", false)
+			if err := Run(context.Background(), rw, d, Options{Part: tt.part, Root: root}); err != nil {
+				t.Fatal(err)
+			}
+			if err := rw.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Run() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_RunErrors(t *testing.T) {
+	rw, _ := NewResultWriter(&bytes.Buffer{}, "text", false)
+	if err := Run(context.Background(), rw, Day{}, Options{Dataset: "missing", Root: t.TempDir()}); err == nil {
+		t.Error("Run() with a missing dataset should fail")
+	}
+	if err := Run(context.Background(), rw, Day{}, Options{Part: 3}); err == nil {
+		t.Error("Run() with part 3 should fail")
+	}
+
+	root := t.TempDir()
+	bad := errors.New("bad input")
+	d := Day{Number: 2, Part1: func(ctx context.Context, lines []string) (int, error) { return 0, bad }}
+	if err := os.MkdirAll(filepath.Join(root, "day02", "data"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(DataPath(root, d, "sample"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := Run(context.Background(), rw, d, Options{Root: root})
+	if !errors.Is(err, bad) {
+		t.Errorf("Run() = %v, want the solver's error", err)
+	}
+	if want := "day02 part 1: sample: bad input"; err != nil && err.Error() != want {
+		t.Errorf("Run() = %q, want %q", err, want)
+	}
+	var pe *PartError
+	if !errors.As(err, &pe) || pe.Day != 2 || pe.Part != 1 || pe.Dataset != "sample" {
+		t.Errorf("Run() = %#v, want a *PartError for day 2 part 1 on sample", err)
+	}
+}
+
+func Test_Solve(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	tests := []struct {
+		name    string
+		f       PartFunc
+		want    int
+		wantErr string
+	}{
+		{"fast", func(ctx context.Context, lines []string) (int, error) { return 42, nil }, 42, ""},
+		{"checks", func(ctx context.Context, lines []string) (int, error) {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}, 0, "gave up after 10ms: context deadline exceeded"},
+		{"ignores", func(ctx context.Context, lines []string) (int, error) {
+			<-block
+			return 1, nil
+		}, 0, "gave up after 10ms: context deadline exceeded"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Solve(context.Background(), tt.f, nil, 10*time.Millisecond)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr || !errors.Is(err, context.DeadlineExceeded) {
+					t.Fatalf("Solve() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Solve() = %d, %v, want %d", got, err, tt.want)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Solve(ctx, func(ctx context.Context, lines []string) (int, error) {
+		<-block
+		return 1, nil
+	}, nil, 0); !errors.Is(err, context.Canceled) {
+		t.Errorf("Solve() with a cancelled context = %v, want context.Canceled", err)
+	}
+}
+
+func Test_Params(t *testing.T) {
+	var p Params
+	for _, s := range []string{"vocab=french", "bag=12red,13green", "empty="} {
+		if err := p.Set(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := p.String(), "bag=12red,13green,empty=,vocab=french"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	for _, s := range []string{"vocab", "=french"} {
+		if err := p.Set(s); err == nil {
+			t.Errorf("Set(%q) should fail", s)
+		}
+	}
+
+	var got []string
+	d := Day{
+		Number: 1,
+		Part1: func(ctx context.Context, lines []string) (int, error) {
+			v, ok := Param(ctx, "vocab")
+			_, missing := Param(ctx, "missing")
+			got = append(got, fmt.Sprintln(v, ok, missing))
+			return 0, nil
+		},
+		Params: map[string]string{"vocab": "words"},
+	}
+	input := filepath.Join(t.TempDir(), "input.txt")
+	if err := os.WriteFile(input, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rw, _ := NewResultWriter(io.Discard, "text", false)
+	if err := Run(context.Background(), rw, d, Options{Dataset: input, Params: p}); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := Param(context.Background(), "vocab"); v != "" || ok {
+		t.Errorf("Param() without params = %q, %v", v, ok)
+	}
+	if want := []string{"french true false\n"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("the solver saw %v, want %v", got, want)
+	}
+
+	if err := CheckParams([]Day{{}, d}, Params{"vocab": "x"}); err != nil {
+		t.Errorf("CheckParams() = %v", err)
+	}
+	if err := CheckParams([]Day{d}, Params{"bag": "x"}); err == nil {
+		t.Error("CheckParams() should reject a parameter no day takes")
+	}
+}
+
+func Test_ResultWriter(t *testing.T) {
+	results := []Result{
+		{Day: 7, Part: 1, Dataset: "sample", Answer: 6440, Duration: 1500},
+		{Day: 7, Part: 2, Dataset: "sample", Answer: 5905, Duration: 2500},
+	}
+	tests := []struct {
+		format string
+		timed  bool
+		want   string
+	}{
+		{"text", false, "day07 part 1: 6440\nday07 part 2: 5905\n"},
+		{"text", true, "day07 part 1: 6440 (1.5µs)\nday07 part 2: 5905 (2.5µs)\n"},
+		{"ndjson", false, `{"day":7,"part":1,"dataset":"sample","answer":6440,"duration_ns":1500}` + "\n" +
+			`{"day":7,"part":2,"dataset":"sample","answer":5905,"duration_ns":2500}` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			rw, err := NewResultWriter(&buf, tt.format, tt.timed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, r := range results {
+				if err := rw.Write(r); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := rw.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	rw, _ := NewResultWriter(&buf, "json", false)
+	for _, r := range results {
+		rw.Write(r)
+	}
+	if buf.Len() != 0 {
+		t.Error("json should not be written until Close")
+	}
+	rw.Close()
+	var got []Result
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, results) {
+		t.Errorf("json round trip = %v, want %v", got, results)
+	}
+
+	if _, err := NewResultWriter(&buf, "xml", false); err == nil {
+		t.Error("NewResultWriter() with an unknown format should fail")
+	}
+}
+
+func Test_LogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(NewLogHandler(&buf, slog.LevelInfo))
+	log.Debug("hidden")
+	log.Info("hand", "cards", "32T3K", "bid", 765)
+	log.With("day", 14).Warn("tilted", "dish", "O.#\n.O.\n")
+	want := "INFO hand cards=32T3K bid=765\n" +
+		"WARN tilted day=14\n" +
+		"O.#\n.O.\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func Test_Lines(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{"empty", "", nil},
+		{"one", "a", []string{"a"}},
+		{"newline", "a\nb\n", []string{"a", "b"}},
+		{"no newline", "a\nb", []string{"a", "b"}},
+		{"crlf", "a\r\nb\r\n", []string{"a", "b"}},
+		{"mixed", "a\r\nb\nc", []string{"a", "b", "c"}},
+		{"trailing blanks", "a\n\n \r\n\n", []string{"a"}},
+		{"inner blanks", "a\n\n\r\nb\n\n", []string{"a", "", "", "b"}},
+		{"leading blanks", "\n\na", []string{"", "", "a"}},
+		{"only blanks", "\n\n\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Lines([]byte(tt.in)); !slices.Equal(got, tt.want) {
+				t.Errorf("Lines() = %q, want %q", got, tt.want)
+			}
+			got, err := Read(strings.NewReader(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Read() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Scanner(t *testing.T) {
+	long := strings.Repeat("rn=1,", 100000)
+	s := NewScanner(strings.NewReader("x\r\n\n" + long + "\n\n"))
+	var got []string
+	var nums []int
+	for s.Scan() {
+		got = append(got, s.Text())
+		nums = append(nums, s.Line())
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, []string{"x", "", long}) || !slices.Equal(nums, []int{1, 2, 3}) {
+		t.Errorf("got %d lines numbered %v", len(got), nums)
+	}
+
+	bad := errors.New("disk on fire")
+	s = NewScanner(io.MultiReader(strings.NewReader("a\nb\n"), iotest.ErrReader(bad)))
+	n := 0
+	for s.Scan() {
+		n++
+	}
+	if n != 2 || !errors.Is(s.Err(), bad) {
+		t.Errorf("read %d lines with error %v, want 2 and %v", n, s.Err(), bad)
+	}
+	if _, err := Read(iotest.ErrReader(bad)); !errors.Is(err, bad) {
+		t.Errorf("Read() = %v, want %v", err, bad)
+	}
+}
+
+func Test_ReadMatchesLines(t *testing.T) {
+	inputs := []string{
+		"a\n   \nb\n",
+		"a\r\n\t\r\n\r\n b \r\n  \r\n",
+		"\n  \na",
+		"a\n \n\n",
+		"",
+	}
+	for _, in := range inputs {
+		t.Run(fmt.Sprintf("%q", in), func(t *testing.T) {
+			got, err := Read(strings.NewReader(in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := Lines([]byte(in)); !slices.Equal(got, want) {
+				t.Errorf("Read() = %q, Lines() = %q", got, want)
+			}
+		})
+	}
+}
+
+func Test_IsPath(t *testing.T) {
+	tests := []struct {
+		dataset string
+		want    bool
+	}{
+		{"input", false},
+		{"sample2", false},
+		{"-", false},
+		{"gen.txt", true},
+		{"./gen", true},
+		{"/tmp/big", true},
+		{"../day07/data/input.txt", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dataset, func(t *testing.T) {
+			if got := IsPath(tt.dataset); got != tt.want {
+				t.Errorf("IsPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Open(t *testing.T) {
+	root := t.TempDir()
+	d := Day{Number: 4, Default: "sample2"}
+	if err := os.MkdirAll(filepath.Join(root, "day04", "data"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		DataPath(root, d, "sample2"):   "default\n",
+		DataPath(root, d, "input"):     "input\n",
+		filepath.Join(root, "gen.txt"): "generated\r\n",
+	}
+	for path, s := range files {
+		if err := os.WriteFile(path, []byte(s), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.WriteString("piped\n")
+	w.Close()
+	stdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = stdin }()
+
+	tests := []struct {
+		dataset string
+		name    string
+		line    string
+	}{
+		{"", "sample2", "default"},
+		{"input", "input", "input"},
+		{filepath.Join(root, "gen.txt"), filepath.Join(root, "gen.txt"), "generated"},
+		{"-", "stdin", "piped"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, lines, err := Open(root, d, tt.dataset)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if name != tt.name || !slices.Equal(lines, []string{tt.line}) {
+				t.Errorf("Open() = %q, %q, want %q, [%q]", name, lines, tt.name, tt.line)
+			}
+		})
+	}
+	if _, _, err := Open(root, d, "missing"); err == nil {
+		t.Error("Open() of a missing dataset should fail")
+	}
+	if _, _, err := Open(root, d, filepath.Join(root, "missing.txt")); err == nil {
+		t.Error("Open() of a missing file should fail")
+	}
+}
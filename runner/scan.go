@@ -0,0 +1,89 @@
+package runner
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// Scanner reads an input a line at a time. It cleans up the lines the same
+// way Lines does: a \r before the \n is dropped, and blank lines at the end
+// of the input are left out. Unlike bufio.Scanner, it has no limit on the
+// length of a line.
+type Scanner struct {
+	r    *bufio.Reader
+	line string
+	n    int
+	err  error
+	done bool
+	// blank lines are held back until a line that isn't blank turns up,
+	// and then returned as they were before it
+	blanks []string
+	next   string
+	held   bool
+}
+
+// NewScanner returns a Scanner reading from r.
+func NewScanner(r io.Reader) *Scanner {
+	return &Scanner{r: bufio.NewReader(r)}
+}
+
+// Scan moves to the next line, which is then available through Text. It
+// returns false at the end of the input or on an error.
+func (s *Scanner) Scan() bool {
+	if s.held {
+		if len(s.blanks) > 0 {
+			s.set(s.blanks[0])
+			s.blanks = s.blanks[1:]
+			return true
+		}
+		s.held = false
+		s.set(s.next)
+		return true
+	}
+	for !s.done {
+		line, err := s.r.ReadString('\n')
+		if err != nil {
+			s.done = true
+			if err != io.EOF {
+				s.err = err
+				return false
+			}
+			if line == "" {
+				break
+			}
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		if strings.TrimSpace(line) == "" {
+			s.blanks = append(s.blanks, line)
+			continue
+		}
+		if len(s.blanks) > 0 {
+			s.next, s.held = line, true
+			return s.Scan()
+		}
+		s.set(line)
+		return true
+	}
+	return false
+}
+
+func (s *Scanner) set(line string) {
+	s.line = line
+	s.n++
+}
+
+// Text returns the current line, without its line ending.
+func (s *Scanner) Text() string {
+	return s.line
+}
+
+// Line returns the number of the current line, counting from 1.
+func (s *Scanner) Line() int {
+	return s.n
+}
+
+// Err returns the first error other than io.EOF that the Scanner hit.
+func (s *Scanner) Err() error {
+	return s.err
+}
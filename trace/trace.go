@@ -0,0 +1,192 @@
+// Package trace replaces the commented-out Println calls that used to be
+// toggled by editing code. Each day declares the topics it can trace,
+//
+//	var tcaf = trace.New("day12", "caf")
+//
+// and guards its output with them:
+//
+//	tcaf.Printf("countArrangements(%v, %d)", groups, start)
+//
+// Topics are off until turned on with Enable, which the aoc command calls for
+// its -trace flag, or with the AOC_TRACE environment variable. Both take a
+// comma-separated list such as "day12.caf,day05". A topic can be given a
+// verbosity, as in "day09.triangle=2", for output that is only worth seeing
+// some of the time; V checks it.
+//
+// Trace output goes to the default slog logger at LevelTrace, whatever that
+// logger's level is, since turning a topic on is what asks for it.
+package trace
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LevelTrace is the level trace records are logged at.
+const LevelTrace = slog.LevelDebug - 4
+
+// A Topic is one thing a day can trace.
+type Topic struct {
+	name  string
+	level atomic.Int32
+}
+
+type rule struct {
+	pattern string
+	level   int
+}
+
+var (
+	mu     sync.Mutex
+	topics = make(map[string]*Topic)
+	rules  []rule
+)
+
+func init() {
+	if spec := os.Getenv("AOC_TRACE"); spec != "" {
+		if err := Enable(spec); err != nil {
+			fmt.Fprintf(os.Stderr, "AOC_TRACE: %v\n", err)
+		}
+	}
+}
+
+// New returns the topic named day.topic, creating it if need be.
+func New(day, topic string) *Topic {
+	name := day + "." + topic
+	mu.Lock()
+	defer mu.Unlock()
+	if t, ok := topics[name]; ok {
+		return t
+	}
+	t := &Topic{name: name}
+	t.level.Store(int32(levelFor(name)))
+	topics[name] = t
+	return t
+}
+
+// levelFor returns the verbosity the current rules give to the named topic;
+// the last matching rule wins. mu must be held.
+func levelFor(name string) int {
+	level := 0
+	for _, r := range rules {
+		if ok, _ := path.Match(r.pattern, name); ok {
+			level = r.level
+		}
+	}
+	return level
+}
+
+// parse turns a spec into rules. A bare day such as "day12" means all of
+// its topics.
+func parse(spec string) ([]rule, error) {
+	var rs []rule
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		r := rule{pattern: item, level: 1}
+		if p, l, ok := strings.Cut(item, "="); ok {
+			n, err := strconv.Atoi(l)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("bad verbosity in %q", item)
+			}
+			r.pattern, r.level = p, n
+		}
+		if !strings.Contains(r.pattern, ".") && r.pattern != "*" {
+			r.pattern += ".*"
+		}
+		if r.pattern == "*" {
+			r.pattern = "*.*"
+		}
+		if _, err := path.Match(r.pattern, ""); err != nil {
+			return nil, fmt.Errorf("bad pattern %q", item)
+		}
+		rs = append(rs, r)
+	}
+	return rs, nil
+}
+
+// Enable adds the topics described by spec to those already on.
+func Enable(spec string) error {
+	rs, err := parse(spec)
+	if err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	rules = append(rules, rs...)
+	for name, t := range topics {
+		t.level.Store(int32(levelFor(name)))
+	}
+	return nil
+}
+
+// Reset turns every topic off.
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	rules = nil
+	for _, t := range topics {
+		t.level.Store(0)
+	}
+}
+
+// Topics returns the names of every topic that has been declared, in order.
+func Topics() []string {
+	mu.Lock()
+	defer mu.Unlock()
+	names := make([]string, 0, len(topics))
+	for name := range topics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Name returns the topic's full name, such as "day12.caf".
+func (t *Topic) Name() string {
+	return t.name
+}
+
+// Enabled reports whether the topic is on at all.
+func (t *Topic) Enabled() bool {
+	return t.V(1)
+}
+
+// V reports whether the topic is on at verbosity n or higher.
+func (t *Topic) V(n int) bool {
+	return int(t.level.Load()) >= n
+}
+
+// Printf logs a formatted message if the topic is on.
+func (t *Topic) Printf(format string, args ...any) {
+	if !t.Enabled() {
+		return
+	}
+	t.emit(fmt.Sprintf(format, args...))
+}
+
+// Log logs a message with slog-style key/value pairs if the topic is on.
+func (t *Topic) Log(msg string, args ...any) {
+	if !t.Enabled() {
+		return
+	}
+	t.emit(msg, args...)
+}
+
+func (t *Topic) emit(msg string, args ...any) {
+	r := slog.NewRecord(time.Now(), LevelTrace, msg, 0)
+	r.AddAttrs(slog.String("trace", t.name))
+	r.Add(args...)
+	_ = slog.Default().Handler().Handle(context.Background(), r)
+}
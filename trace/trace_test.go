@@ -0,0 +1,127 @@
+package trace
+
+import (
+	"bytes"
+	"log/slog"
+	"reflect"
+	"testing"
+)
+
+func Test_parse(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    []rule
+		wantErr bool
+	}{
+		{"topic", "day12.caf", []rule{{"day12.caf", 1}}, false},
+		{"day", "day05", []rule{{"day05.*", 1}}, false},
+		{"everything", "*", []rule{{"*.*", 1}}, false},
+		{"verbosity", "day09.triangle=2", []rule{{"day09.triangle", 2}}, false},
+		{"list", "day05, day12.caf=0,", []rule{{"day05.*", 1}, {"day12.caf", 0}}, false},
+		{"empty", "", nil, false},
+		{"bad verbosity", "day09.triangle=x", nil, true},
+		{"negative verbosity", "day09.triangle=-1", nil, true},
+		{"bad pattern", "day[", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parse(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Enable(t *testing.T) {
+	defer Reset()
+	caf := New("day12", "caf")
+	row := New("day12", "row")
+	tri := New("day09", "triangle")
+	if New("day12", "caf") != caf {
+		t.Error("New() should return the existing topic")
+	}
+
+	tests := []struct {
+		name string
+		spec string
+		caf  int
+		row  int
+		tri  int
+	}{
+		{"nothing", "", 0, 0, 0},
+		{"one topic", "day12.caf", 1, 0, 0},
+		{"whole day", "day12", 1, 1, 0},
+		{"verbosity", "day09.triangle=2", 0, 0, 2},
+		{"last wins", "day12=3,day12.row=0", 3, 0, 0},
+		{"everything", "*", 1, 1, 1},
+	}
+	level := func(tp *Topic) int {
+		n := 0
+		for tp.V(n + 1) {
+			n++
+		}
+		return n
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Reset()
+			if err := Enable(tt.spec); err != nil {
+				t.Fatal(err)
+			}
+			if got := level(caf); got != tt.caf {
+				t.Errorf("day12.caf at %d, want %d", got, tt.caf)
+			}
+			if got := level(row); got != tt.row {
+				t.Errorf("day12.row at %d, want %d", got, tt.row)
+			}
+			if got := level(tri); got != tt.tri {
+				t.Errorf("day09.triangle at %d, want %d", got, tt.tri)
+			}
+		})
+	}
+
+	Reset()
+	if err := Enable("day99"); err != nil {
+		t.Fatal(err)
+	}
+	if !New("day99", "late").Enabled() {
+		t.Error("a topic declared after Enable should pick up the rules")
+	}
+}
+
+func Test_Printf(t *testing.T) {
+	defer Reset()
+	defer slog.SetDefault(slog.Default())
+	var buf bytes.Buffer
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelError,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})))
+
+	tp := New("day05", "convert")
+	Reset()
+	tp.Printf("seed(%d)", 79)
+	if buf.Len() != 0 {
+		t.Errorf("a topic that is off wrote %q", buf.String())
+	}
+	if err := Enable("day05"); err != nil {
+		t.Fatal(err)
+	}
+	tp.Printf("seed(%d)", 79)
+	tp.Log("END", "location", 82)
+	want := "level=DEBUG-4 msg=seed(79) trace=day05.convert\n" +
+		"level=DEBUG-4 msg=END trace=day05.convert location=82\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
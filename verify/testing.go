@@ -0,0 +1,88 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/kentquirk/aoc2023/runner"
+)
+
+// Test checks day n against its known answers, with a subtest for each
+// dataset and part. It is called from the answers_test.go file that
+// "aoc gentests" writes into each day. The full inputs are skipped with
+// -short because some of them take a while.
+//
+// Each dataset is tried with each of the Endings, so the answers don't
+// depend on how the file happened to be saved.
+func Test(t *testing.T, root string, n int) {
+	t.Helper()
+	d, ok := runner.Lookup(n)
+	if !ok {
+		t.Fatalf("day %d is not registered", n)
+	}
+	answers, err := Load(root, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range answers.Datasets() {
+		t.Run(name, func(t *testing.T) {
+			if testing.Short() && name == "input" {
+				t.Skip("skipping the full input in short mode")
+			}
+			b, err := os.ReadFile(runner.DataPath(root, d, name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, e := range Endings {
+				t.Run(e.Name, func(t *testing.T) {
+					lines, err := runner.Read(bytes.NewReader(e.Apply(b)))
+					if err != nil {
+						t.Fatal(err)
+					}
+					for _, p := range answers.Parts(name) {
+						t.Run(fmt.Sprintf("part%d", p), func(t *testing.T) {
+							f := d.Part(p)
+							if f == nil {
+								t.Fatalf("no solver for part %d", p)
+							}
+							got, err := f(context.Background(), lines)
+							if err != nil {
+								t.Fatalf("%s part %d: %v", name, p, err)
+							}
+							if want := answers[name][p]; got != want {
+								t.Errorf("%s part %d = %d, want %d", name, p, got, want)
+							}
+						})
+					}
+				})
+			}
+		})
+	}
+}
+
+// Ending is a way an input file might have been saved.
+type Ending struct {
+	Name  string
+	Apply func(b []byte) []byte
+}
+
+// Endings are the line endings Test tries each dataset with.
+var Endings = []Ending{
+	{"no newline", func(b []byte) []byte {
+		return bare(b)
+	}},
+	{"newline", func(b []byte) []byte {
+		return append(bare(b), '\n')
+	}},
+	{"crlf", func(b []byte) []byte {
+		return append(bytes.ReplaceAll(bare(b), []byte("\n"), []byte("\r\n")), '\r', '\n')
+	}},
+}
+
+// bare returns b with \n line endings and no newline at the end.
+func bare(b []byte) []byte {
+	return bytes.TrimRight(bytes.ReplaceAll(b, []byte("\r\n"), []byte("\n")), "\n")
+}
@@ -0,0 +1,131 @@
+// Package verify checks the solvers against the answers we already know are
+// right. Each day keeps them in data/answers.json, keyed by dataset and then
+// by part:
+//
+//	{
+//	  "sample": {"1": 142, "2": 281},
+//	  "input": {"1": 55701}
+//	}
+//
+// Only the parts listed are checked, so a sample that only applies to one
+// part just leaves the other out.
+package verify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/kentquirk/aoc2023/runner"
+)
+
+// Answers maps a dataset name to the known answer for each part.
+type Answers map[string]map[int]int
+
+// Path returns the location of d's answers file below root.
+func Path(root string, d runner.Day) string {
+	return filepath.Join(root, d.Name(), "data", "answers.json")
+}
+
+// Load reads the answers for d. A day without an answers file has no known
+// answers, which is not an error.
+func Load(root string, d runner.Day) (Answers, error) {
+	b, err := os.ReadFile(Path(root, d))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Answers{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var a Answers
+	if err := json.Unmarshal(b, &a); err != nil {
+		return nil, fmt.Errorf("%s: %w", Path(root, d), err)
+	}
+	return a, nil
+}
+
+// Datasets returns the names of the datasets with known answers, in order.
+func (a Answers) Datasets() []string {
+	names := make([]string, 0, len(a))
+	for name := range a {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Parts returns the parts with known answers for a dataset, in order.
+func (a Answers) Parts(dataset string) []int {
+	parts := make([]int, 0, 2)
+	for p := range a[dataset] {
+		parts = append(parts, p)
+	}
+	sort.Ints(parts)
+	return parts
+}
+
+// Result is the outcome of checking one part against one dataset.
+type Result struct {
+	Day     runner.Day
+	Dataset string
+	Part    int
+	Want    int
+	Got     int
+	// Err is the error the solver returned, if any.
+	Err error
+}
+
+// OK reports whether the solver produced the known answer.
+func (r Result) OK() bool {
+	return r.Err == nil && r.Got == r.Want
+}
+
+func (r Result) String() string {
+	if r.Err != nil {
+		return fmt.Sprintf("FAIL %s %s part %d: %v", r.Day.Name(), r.Dataset, r.Part, r.Err)
+	}
+	if r.OK() {
+		return fmt.Sprintf("ok   %s %s part %d: %d", r.Day.Name(), r.Dataset, r.Part, r.Got)
+	}
+	return fmt.Sprintf("FAIL %s %s part %d: got %d, want %d", r.Day.Name(), r.Dataset, r.Part, r.Got, r.Want)
+}
+
+// Check runs each solver of d against every dataset that has a known answer.
+// If skip is not nil, datasets for which it returns true are left out.
+func Check(ctx context.Context, root string, d runner.Day, skip func(dataset string) bool) ([]Result, error) {
+	answers, err := Load(root, d)
+	if err != nil {
+		return nil, err
+	}
+	var results []Result
+	for _, name := range answers.Datasets() {
+		if skip != nil && skip(name) {
+			continue
+		}
+		lines, err := runner.Load(root, d, name)
+		if err != nil {
+			return results, err
+		}
+		for _, p := range answers.Parts(name) {
+			f := d.Part(p)
+			if f == nil {
+				return results, fmt.Errorf("%s has an answer for part %d but no solver", d.Name(), p)
+			}
+			got, err := f(ctx, lines)
+			results = append(results, Result{
+				Day:     d,
+				Dataset: name,
+				Part:    p,
+				Want:    answers[name][p],
+				Got:     got,
+				Err:     err,
+			})
+		}
+	}
+	return results, nil
+}
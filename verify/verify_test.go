@@ -0,0 +1,96 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kentquirk/aoc2023/runner"
+)
+
+func Test_Check(t *testing.T) {
+	root := t.TempDir()
+	d := runner.Day{
+		Number: 3,
+		Part1:  func(ctx context.Context, lines []string) (int, error) { return len(lines), nil },
+		Part2:  func(ctx context.Context, lines []string) (int, error) { return 0, nil },
+	}
+	dir := filepath.Join(root, "day03", "data")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"sample.txt":   "a\nb",
+		"input.txt":    "a\nb\nc",
+		"answers.json": `{"sample": {"1": 2, "2": 0}, "input": {"1": 4}}`,
+	}
+	for name, s := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(s), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		skip  func(string) bool
+		count int
+		fails int
+	}{
+		{"all", nil, 3, 1},
+		{"short", func(s string) bool { return s == "input" }, 2, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := Check(context.Background(), root, d, tt.skip)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fails := 0
+			for _, r := range results {
+				if !r.OK() {
+					fails++
+				}
+			}
+			if len(results) != tt.count || fails != tt.fails {
+				t.Errorf("Check() gave %d results with %d failures, want %d with %d", len(results), fails, tt.count, tt.fails)
+			}
+		})
+	}
+}
+
+func Test_LoadMissing(t *testing.T) {
+	a, err := Load(t.TempDir(), runner.Day{Number: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a) != 0 {
+		t.Errorf("Load() = %v, want no answers", a)
+	}
+}
+
+func Test_ResultErr(t *testing.T) {
+	r := Result{Day: runner.Day{Number: 8}, Dataset: "input", Part: 2, Err: errors.New("line 3: bad node")}
+	if r.OK() {
+		t.Error("a Result with an error should not be OK")
+	}
+	if got, want := r.String(), "FAIL day08 input part 2: line 3: bad node"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func Test_Endings(t *testing.T) {
+	want := map[string]string{
+		"no newline": "a\nb",
+		"newline":    "a\nb\n",
+		"crlf":       "a\r\nb\r\n",
+	}
+	for _, in := range []string{"a\nb", "a\nb\n", "a\r\nb\r\n\n"} {
+		for _, e := range Endings {
+			if got := string(e.Apply([]byte(in))); got != want[e.Name] {
+				t.Errorf("%s(%q) = %q, want %q", e.Name, in, got, want[e.Name])
+			}
+		}
+	}
+}